@@ -9,11 +9,33 @@ import (
 
 	gnfinder "github.com/gnames/gnfinder/pkg"
 	"github.com/gnames/gnfinder/pkg/config"
+	"github.com/gnames/gnfinder/pkg/ent/checklist"
+	"github.com/gnames/gnfinder/pkg/ent/graph"
 	"github.com/gnames/gnfinder/pkg/ent/lang"
+	"github.com/gnames/gnfinder/pkg/ent/markup"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/gnames/gnfinder/pkg/ent/order"
+	"github.com/gnames/gnfinder/pkg/ent/preprocess"
+	"github.com/gnames/gnfinder/pkg/ent/section"
+	"github.com/gnames/gnfinder/pkg/ent/segment"
 	"github.com/gnames/gnfmt"
 	"github.com/spf13/cobra"
 )
 
+func aggregateFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("aggregate-names")
+	if b {
+		opts = append(opts, config.OptWithAggregatedNames(b))
+	}
+}
+
+func allCapsFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("all-caps")
+	if b {
+		opts = append(opts, config.OptWithAllCaps(b))
+	}
+}
+
 func ambiguousUninomialsFlag(cmd *cobra.Command) {
 	b, _ := cmd.Flags().GetBool("ambiguous-uninomials")
 	if b {
@@ -42,6 +64,51 @@ func bytesOffsetFlag(cmd *cobra.Command) {
 	}
 }
 
+func checklistFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("checklist")
+	if s == "" {
+		return
+	}
+	m, err := checklist.New(s)
+	if err != nil {
+		slog.Warn("Supported checklist modes", "modes", checklist.ModeStrings())
+		slog.Info("Switching to running text.")
+	}
+	opts = append(opts, config.OptChecklist(m))
+}
+
+func checklistColumnFlag(cmd *cobra.Command) {
+	i, _ := cmd.Flags().GetInt("checklist-column")
+	if i > 0 {
+		opts = append(opts, config.OptChecklistColumn(i))
+	}
+}
+
+func contextFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("context")
+	if s == "" {
+		return
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		opts = append(opts,
+			config.OptContextMode(segment.Characters), config.OptContextSize(i))
+		return
+	}
+	m, err := segment.NewContextMode(s)
+	if err != nil {
+		slog.Warn("Supported context modes", "modes", segment.ContextModeStrings())
+		slog.Info("Switching to words around names.")
+	}
+	opts = append(opts, config.OptContextMode(m))
+}
+
+func linePositionsFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("line-positions")
+	if b {
+		opts = append(opts, config.OptWithLinePositions(true))
+	}
+}
+
 func formatFlag(cmd *cobra.Command) {
 	format := gnfmt.CSV
 	s, _ := cmd.Flags().GetString("format")
@@ -61,6 +128,75 @@ func formatFlag(cmd *cobra.Command) {
 	opts = append(opts, config.OptFormat(format))
 }
 
+func excludeSectionsFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("exclude-sections")
+	if s == "" {
+		return
+	}
+	ss := parseSections(strings.Split(s, ","))
+	opts = append(opts, config.OptExcludeSections(ss))
+}
+
+func fuzzyFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("fuzzy")
+	if b {
+		opts = append(opts, config.OptWithFuzzyMatch(b))
+	}
+}
+
+func graphFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("graph")
+	if s == "" {
+		return
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		opts = append(opts,
+			config.OptGraphWindow(graph.Tokens), config.OptGraphTokens(i))
+		return
+	}
+	w, err := graph.NewWindow(s)
+	if err != nil {
+		slog.Warn("Supported co-occurrence windows",
+			"windows", graph.WindowStrings())
+		slog.Info("Switching off the co-occurrence graph.")
+	}
+	opts = append(opts, config.OptGraphWindow(w))
+}
+
+func graphFormatFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("graph-format")
+	if s == "" {
+		return
+	}
+	f, err := graph.NewFormat(s)
+	if err != nil {
+		slog.Warn("Supported graph formats", "formats", graph.FormatStrings())
+		slog.Info("Switching to JSON graph.")
+	}
+	opts = append(opts, config.OptGraphFormat(f))
+}
+
+func hierarchyFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("hierarchy")
+	if b {
+		opts = append(opts, config.OptWithHierarchy(b))
+	}
+}
+
+func layoutCleanupFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("layout-cleanup")
+	if b {
+		opts = append(opts, config.OptWithLayoutCleanup(b))
+	}
+}
+
+func historicFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("historic")
+	if b {
+		opts = append(opts, config.OptWithHistoricText(b))
+	}
+}
+
 func inputFlag(cmd *cobra.Command) {
 	b, _ := cmd.Flags().GetBool("input")
 	if b {
@@ -87,6 +223,53 @@ func langFlag(cmd *cobra.Command) {
 	opts = append(opts, config.OptLanguage(l))
 }
 
+func markupFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("markup")
+	if s == "" {
+		return
+	}
+	m, err := markup.New(s)
+	if err != nil {
+		slog.Warn("Supported markups", "markups", markup.MarkupStrings())
+		slog.Info("Switching to plain text input.")
+	}
+	opts = append(opts, config.OptMarkup(m))
+}
+
+func offsetUnitFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("offset-unit")
+	if s == "" {
+		return
+	}
+	u, err := offset.New(s)
+	if err != nil {
+		slog.Warn("Supported offset units", "units", offset.UnitStrings())
+		slog.Info("Switching to offsets in UTF-8 characters.")
+	}
+	opts = append(opts, config.OptOffsetUnit(u))
+}
+
+func pageMarkerFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("page-marker")
+	if s != "" {
+		opts = append(opts, config.OptPageMarker(s))
+	}
+}
+
+func unicodeFormFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("unicode-form")
+	if s == "" {
+		return
+	}
+	f, err := preprocess.New(s)
+	if err != nil {
+		slog.Warn("Supported normalization forms",
+			"forms", preprocess.FormStrings())
+		slog.Info("Switching to NFC normalization.")
+	}
+	opts = append(opts, config.OptUnicodeForm(f))
+}
+
 func allMatchesFlag(cmd *cobra.Command) {
 	b, _ := cmd.Flags().GetBool("all-matches")
 	if b {
@@ -130,6 +313,20 @@ func tikaURLFlag(cmd *cobra.Command) {
 	}
 }
 
+func tablesFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("tables")
+	if b {
+		opts = append(opts, config.OptWithTables(b))
+	}
+}
+
+func typeMaterialFlag(cmd *cobra.Command) {
+	b, _ := cmd.Flags().GetBool("type-material")
+	if b {
+		opts = append(opts, config.OptWithTypeMaterial(b))
+	}
+}
+
 func uniqueFlag(cmd *cobra.Command) {
 	b, _ := cmd.Flags().GetBool("unique-names")
 	if b {
@@ -137,6 +334,19 @@ func uniqueFlag(cmd *cobra.Command) {
 	}
 }
 
+func sortFlag(cmd *cobra.Command) {
+	s, _ := cmd.Flags().GetString("sort")
+	if s == "" {
+		return
+	}
+	o, err := order.New(s)
+	if err != nil {
+		slog.Warn("Supported orders of unique names", "orders", order.OrderStrings())
+		slog.Info("Sorting unique names alphabetically.")
+	}
+	opts = append(opts, config.OptUniqueNamesOrder(o))
+}
+
 func verifFlag(cmd *cobra.Command) {
 	b, _ := cmd.Flags().GetBool("verify")
 	if b {
@@ -167,6 +377,22 @@ func wordsFlag(cmd *cobra.Command) {
 	}
 }
 
+// parseSections converts names of sections to sections. Unknown names
+// are skipped.
+func parseSections(ss []string) []section.Section {
+	var res []section.Section
+	for _, v := range ss {
+		s, err := section.New(v)
+		if err != nil {
+			slog.Warn("Unknown section, skipping", "section", v,
+				"sections", section.SectionStrings())
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
 func parseDataSources(s string) ([]int, error) {
 	dss := strings.Split(s, ",")
 	res := make([]int, 0, len(dss))
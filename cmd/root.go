@@ -21,7 +21,9 @@
 package cmd
 
 import (
+	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -29,14 +31,23 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gnames/gndoc"
 	gnfinder "github.com/gnames/gnfinder/pkg"
 	"github.com/gnames/gnfinder/pkg/config"
+	"github.com/gnames/gnfinder/pkg/ent/checklist"
+	"github.com/gnames/gnfinder/pkg/ent/graph"
 	"github.com/gnames/gnfinder/pkg/ent/lang"
+	"github.com/gnames/gnfinder/pkg/ent/markup"
 	"github.com/gnames/gnfinder/pkg/ent/nlp"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/gnames/gnfinder/pkg/ent/order"
+	"github.com/gnames/gnfinder/pkg/ent/preprocess"
+	"github.com/gnames/gnfinder/pkg/ent/segment"
 	"github.com/gnames/gnfinder/pkg/ent/verifier"
 	"github.com/gnames/gnfinder/pkg/io/dict"
+	"github.com/gnames/gnfinder/pkg/io/extract"
 	"github.com/gnames/gnfinder/pkg/io/web"
 	"github.com/gnames/gnfmt"
 	"github.com/gnames/gnsys"
@@ -53,22 +64,46 @@ var opts []config.Option
 // configuration file, if it exists.
 type cfgData struct {
 	BayesOddsThreshold float64
+	Checklist          string
+	ChecklistColumn    int
+	ContextMode        string
+	ContextSize        int
 	DataSources        []int
+	ExcludeSections    []string
 	Format             string
+	GraphFormat        string
+	GraphTokens        int
+	GraphWindow        string
 	IncludeInputText   bool
 	InputTextOnly      bool
+	ItalicsOdds        float64
 	Language           string
+	Markup             string
+	MaxFileSize        int64
+	OffsetUnit         string
+	PageMarker         string
 	// PreferredSources is deprecated
 	PreferredSources     []int
 	TikaURL              string
 	TokensAround         int
+	UnicodeForm          string
+	UniqueNamesOrder     string
 	VerifierURL          string
+	WithAggregatedNames  bool
+	WithAllCaps          bool
 	WithAllMatches       bool
 	WithAmbiguousNames   bool
 	WithBayesOddsDetails bool
+	WithFuzzyMatch       bool
+	WithHierarchy        bool
+	WithHistoricText     bool
+	WithLayoutCleanup    bool
+	WithLinePositions    bool
 	WithOddsAdjustment   bool
 	WithPlainInput       bool
 	WithPositionInBytes  bool
+	WithTables           bool
+	WithTypeMaterial     bool
 	WithUniqueNames      bool
 	WithVerification     bool
 	WithoutBayes         bool
@@ -101,20 +136,40 @@ verification results.
 			os.Exit(0)
 		}
 
+		aggregateFlag(cmd)
+		allCapsFlag(cmd)
 		ambiguousUninomialsFlag(cmd)
 		adjustOddsFlag(cmd)
 		bayesFlag(cmd)
 		bytesOffsetFlag(cmd)
+		checklistFlag(cmd)
+		checklistColumnFlag(cmd)
+		contextFlag(cmd)
+		excludeSectionsFlag(cmd)
 		formatFlag(cmd)
+		fuzzyFlag(cmd)
+		graphFlag(cmd)
+		graphFormatFlag(cmd)
+		hierarchyFlag(cmd)
+		historicFlag(cmd)
 		inputFlag(cmd)
 		inputOnlyFlag(cmd)
 		langFlag(cmd)
+		layoutCleanupFlag(cmd)
+		linePositionsFlag(cmd)
+		markupFlag(cmd)
+		offsetUnitFlag(cmd)
+		pageMarkerFlag(cmd)
 		allMatchesFlag(cmd)
 		oddsDetailsFlag(cmd)
 		plainInputFlag(cmd)
 		sourcesFlag(cmd)
 		tikaURLFlag(cmd)
+		tablesFlag(cmd)
+		typeMaterialFlag(cmd)
+		unicodeFormFlag(cmd)
 		uniqueFlag(cmd)
+		sortFlag(cmd)
 		verifFlag(cmd)
 		verifURLFlag(cmd)
 		wordsFlag(cmd)
@@ -143,7 +198,8 @@ verification results.
 			os.Exit(0)
 		}
 
-		var data, input string
+		var doc extract.Document
+		var input string
 		var rawData []byte
 		var convDur float32
 		switch len(args) {
@@ -156,17 +212,11 @@ verification results.
 			if err != nil {
 				slog.Error("Cannot read data", "error", err)
 			}
-			data = string(rawData)
+			doc.Text = string(rawData)
 			input = "STDIN"
 		case 1:
 			input = args[0]
-			ua := "gnfinder/" + gnfinder.Version + " (https://github.com/gnames/gnfinder)"
-			d := gndoc.New(cfg.TikaURL, gndoc.OptUserAgent(ua))
-			if strings.HasPrefix(input, "http") {
-				data, convDur, err = d.TextFromURL(input)
-			} else {
-				data, convDur, err = d.TextFromFile(input, cfg.WithPlainInput)
-			}
+			doc, convDur, err = getText(input, cfg)
 			if err != nil {
 				slog.Error("Cannot get input", "error", err)
 				os.Exit(1)
@@ -177,11 +227,11 @@ verification results.
 		}
 
 		if cfg.InputTextOnly {
-			fmt.Print(data)
+			fmt.Print(doc.Text)
 			os.Exit(0)
 		}
 
-		findNames(data, cfg, input, convDur)
+		findNames(doc, cfg, input, convDur)
 	},
 }
 
@@ -202,25 +252,78 @@ func init() {
 	rootCmd.Flags().BoolP("adjust-odds", "a", false,
 		"adjust Bayes odds using density of found names.")
 	rootCmd.Flags().BoolP("bytes-offset", "b", false,
-		"names offsets in bytes, not UTF-8 chars.")
+		"names offsets in bytes, not UTF-8 chars (same as '-O bytes').")
+	rootCmd.Flags().StringP("checklist", "k", "",
+		`treat every line or table cell as a separate name candidate:
+  lines: every line of a species list,
+  csv: cells of comma-separated values,
+  tsv: cells of tab-separated values`)
+	rootCmd.Flags().IntP("checklist-column", "K", 0,
+		"use only this column (starting from 1) of a CSV/TSV checklist.")
+	rootCmd.Flags().StringP("context", "C", "",
+		`context of names: "words", "sentence", "paragraph" or a number N.
+  words: words around names set by "--words-around" (DEFAULT),
+  sentence: the sentence of a name,
+  paragraph: the paragraph of a name,
+  N: up to N characters before and after a name`)
 	rootCmd.Flags().BoolP("details-odds", "d", false,
 		"show details of odds calculation.")
 	rootCmd.Flags().StringP("verifier-url", "e", "",
 		"custom URL for name-verification service.")
+	rootCmd.Flags().StringP("exclude-sections", "x", "",
+		`sections of a document where names are not reported (ex "references,key"):
+  abstract, introduction, methods, results, discussion, key,
+  references, acknowledgements`)
 	rootCmd.Flags().StringP("format", "f", "",
 		`Format of the output: "compact", "pretty", "csv".
   compact: compact JSON,
   pretty: pretty JSON,
   csv: CSV (DEFAULT)`)
+	rootCmd.Flags().BoolP("fuzzy", "z", false,
+		"match misspelled words to known genera and epithets.")
+	rootCmd.Flags().StringP("graph", "g", "",
+		`co-occurrence graph of names: "sentence", "paragraph" or a number N.
+  sentence: names of the same sentence,
+  paragraph: names of the same paragraph,
+  N: names separated by no more than N words`)
+	rootCmd.Flags().StringP("graph-format", "G", "",
+		`format of the co-occurrence graph: "json", "graphml", "dot".
+  json: graph is a part of JSON output (DEFAULT),
+  graphml: only the graph in GraphML,
+  dot: only the graph in DOT of Graphviz`)
+	rootCmd.Flags().BoolP("hierarchy", "r", false,
+		"build a tree of taxa from headings of a monograph.")
+	rootCmd.Flags().BoolP("historic", "H", false,
+		"normalize old letters and correct OCR errors of historic texts.")
 	rootCmd.Flags().BoolP("input-only", "I", false,
 		"return only given UTF8-encoded input without finding names.")
 	rootCmd.Flags().BoolP("input", "i", false,
 		"add given input to results.")
 	rootCmd.Flags().StringP("lang", "l", "",
 		"text's language or 'detect' for automatic detection.")
+	rootCmd.Flags().BoolP("layout-cleanup", "c", false,
+		"remove page numbers, running headers and hyphenation of known words.")
+	rootCmd.Flags().BoolP("line-positions", "L", false,
+		"add line and column numbers of names.")
+	rootCmd.Flags().StringP("markup", "m", "",
+		`markup of the input text: "html", "markdown", "jats".
+Italicized words help to find names.`)
 	rootCmd.Flags().BoolP("no-bayes", "n", false, "do not run Bayes algorithms.")
+	rootCmd.Flags().StringP("offset-unit", "O", "",
+		`units of names offsets: "runes", "bytes", "utf16".
+  runes: UTF-8 characters (DEFAULT),
+  bytes: bytes of UTF-8 encoded text,
+  utf16: UTF-16 code units of JavaScript and Java strings`)
+	rootCmd.Flags().StringP("page-marker", "P", "",
+		`regular expression of lines separating pages for layout cleanup,
+for example "^--- Page \d+ ---$".`)
 	rootCmd.Flags().IntP("port",
 		"p", 0, "port to run the gnfinder's RESTful API service.")
+	rootCmd.Flags().StringP("sort", "S", "",
+		`sorting of unique names: "alphabetical", "frequency", "appearance".
+  alphabetical: by names (DEFAULT),
+  frequency: the most frequent names first,
+  appearance: by the first occurrence in the text`)
 	rootCmd.Flags().StringP("sources", "s", "",
 		`IDs of important data-sources to verify against (ex "1,11").
 If sources are set and there are matches to their data,
@@ -239,14 +342,27 @@ To find IDs refer to "https://resolver.globalnames.org/data_sources".
 	rootCmd.Flags().StringP("tika-url", "t", "",
 		`custom URL for the Apache Tika service.
 The service is used for converting files into UTF8-encoded text.`)
+	rootCmd.Flags().Bool("tables", false,
+		"detect tables in running texts and flag names found in them.")
+	rootCmd.Flags().BoolP("type-material", "T", false,
+		"extract type material of names with nomenclatural annotations.")
 	rootCmd.Flags().BoolP("all-matches", "M", false,
 		"verification returns all found matches")
 	rootCmd.Flags().BoolP("utf8-input", "U", false,
 		`affirm that the input is a plain text UTF8 file.
 The direct reading of a file will be used instead of a remote
 Apache Tika service.`)
+	rootCmd.Flags().StringP("unicode-form", "N", "",
+		`Unicode normalization of the text: "nfc", "nfkc", "none".
+  nfc: canonical composition (DEFAULT),
+  nfkc: also normalizes ligatures and full-width letters,
+  none: no normalization`)
 	rootCmd.Flags().BoolP("unique-names", "u", false,
 		"return unique names list")
+	rootCmd.Flags().Bool("aggregate-names", false,
+		"return unique names with counts and offsets of all occurrences.")
+	rootCmd.Flags().Bool("all-caps", false,
+		"find names written in upper case, for example in headings.")
 	rootCmd.Flags().BoolP("verify", "v", false, "verify found name-strings.")
 	rootCmd.Flags().BoolP("version", "V", false, "show version.")
 	rootCmd.Flags().IntP("words-around",
@@ -273,20 +389,44 @@ func initConfig() {
 	// Set environment variables to override
 	// config file settings
 	_ = viper.BindEnv("BayesOddsThreshold", "GNF_BAYES_ODDS_THRESHOLD")
+	_ = viper.BindEnv("Checklist", "GNF_CHECKLIST")
+	_ = viper.BindEnv("ChecklistColumn", "GNF_CHECKLIST_COLUMN")
+	_ = viper.BindEnv("ContextMode", "GNF_CONTEXT_MODE")
+	_ = viper.BindEnv("ContextSize", "GNF_CONTEXT_SIZE")
 	_ = viper.BindEnv("DataSources", "GNF_DATA_SOURCES")
+	_ = viper.BindEnv("ExcludeSections", "GNF_EXCLUDE_SECTIONS")
 	_ = viper.BindEnv("Format", "GNF_FORMAT")
+	_ = viper.BindEnv("GraphFormat", "GNF_GRAPH_FORMAT")
+	_ = viper.BindEnv("GraphTokens", "GNF_GRAPH_TOKENS")
+	_ = viper.BindEnv("GraphWindow", "GNF_GRAPH_WINDOW")
 	_ = viper.BindEnv("InputTextOnly", "GNF_INPUT_TEXT_ONLY")
 	_ = viper.BindEnv("IncludeInputText", "GNF_INCLUDE_INPUT_TEXT")
+	_ = viper.BindEnv("ItalicsOdds", "GNF_ITALICS_ODDS")
 	_ = viper.BindEnv("Language", "GNF_LANGUAGE")
+	_ = viper.BindEnv("Markup", "GNF_MARKUP")
+	_ = viper.BindEnv("MaxFileSize", "GNF_MAX_FILE_SIZE")
+	_ = viper.BindEnv("OffsetUnit", "GNF_OFFSET_UNIT")
+	_ = viper.BindEnv("PageMarker", "GNF_PAGE_MARKER")
 	_ = viper.BindEnv("TikaURL", "GNF_TIKA_URL")
 	_ = viper.BindEnv("TokensAround", "GNF_TOKENS_AROUND")
+	_ = viper.BindEnv("UnicodeForm", "GNF_UNICODE_FORM")
+	_ = viper.BindEnv("UniqueNamesOrder", "GNF_UNIQUE_NAMES_ORDER")
 	_ = viper.BindEnv("VerifierURL", "GNF_VERIFIER_URL")
+	_ = viper.BindEnv("WithAggregatedNames", "GNF_WITH_AGGREGATED_NAMES")
+	_ = viper.BindEnv("WithAllCaps", "GNF_WITH_ALL_CAPS")
 	_ = viper.BindEnv("WithAmbiguousNames", "GNF_WITH_AMBIGUOUS_NAMES")
 	_ = viper.BindEnv("WithAllMatches", "GNF_WITH_ALL_MATCHES")
 	_ = viper.BindEnv("WithBayesOddsDetails", "GNF_WITH_BAYES_ODDS_DETAILS")
+	_ = viper.BindEnv("WithFuzzyMatch", "GNF_WITH_FUZZY_MATCH")
+	_ = viper.BindEnv("WithHierarchy", "GNF_WITH_HIERARCHY")
+	_ = viper.BindEnv("WithHistoricText", "GNF_WITH_HISTORIC_TEXT")
+	_ = viper.BindEnv("WithLayoutCleanup", "GNF_WITH_LAYOUT_CLEANUP")
+	_ = viper.BindEnv("WithLinePositions", "GNF_WITH_LINE_POSITIONS")
 	_ = viper.BindEnv("WithOddsAdjustment", "GNF_WITH_ODDS_ADJUSTMENT")
 	_ = viper.BindEnv("WithPlainInput", "GNF_WITH_PLAIN_INPUT")
 	_ = viper.BindEnv("WithPositionInBytes", "GNF_WITH_POSITION_IN_BYTES")
+	_ = viper.BindEnv("WithTables", "GNF_WITH_TABLES")
+	_ = viper.BindEnv("WithTypeMaterial", "GNF_WITH_TYPE_MATERIAL")
 	_ = viper.BindEnv("WithUniqueNames", "GNF_WITH_UNIQUE_NAMES")
 	_ = viper.BindEnv("WithVerification", "GNF_WITH_VERIFICATION")
 	_ = viper.BindEnv("WithoutBayes", "GNF_WITHOUT_BAYES")
@@ -320,6 +460,40 @@ func getOpts() {
 			config.OptBayesOddsThreshold(cfgCli.BayesOddsThreshold))
 	}
 
+	if cfgCli.ItalicsOdds > 0 {
+		opts = append(opts, config.OptItalicsOdds(cfgCli.ItalicsOdds))
+	}
+
+	if cfgCli.MaxFileSize > 0 {
+		opts = append(opts, config.OptMaxFileSize(cfgCli.MaxFileSize))
+	}
+
+	if cfgCli.Checklist != "" {
+		m, err := checklist.New(cfgCli.Checklist)
+		if err != nil {
+			slog.Warn("Cannot set checklist mode",
+				"checklist", cfgCli.Checklist, "error", err)
+		}
+		opts = append(opts, config.OptChecklist(m))
+	}
+
+	if cfgCli.ChecklistColumn > 0 {
+		opts = append(opts, config.OptChecklistColumn(cfgCli.ChecklistColumn))
+	}
+
+	if cfgCli.ContextMode != "" {
+		m, err := segment.NewContextMode(cfgCli.ContextMode)
+		if err != nil {
+			slog.Warn("Cannot set context mode",
+				"mode", cfgCli.ContextMode, "error", err)
+		}
+		opts = append(opts, config.OptContextMode(m))
+	}
+
+	if cfgCli.ContextSize > 0 {
+		opts = append(opts, config.OptContextSize(cfgCli.ContextSize))
+	}
+
 	if len(cfgCli.DataSources) > 0 || len(cfgCli.PreferredSources) > 0 {
 		ds := cfgCli.DataSources
 		if len(ds) == 0 {
@@ -328,6 +502,11 @@ func getOpts() {
 		opts = append(opts, config.OptDataSources(ds))
 	}
 
+	if len(cfgCli.ExcludeSections) > 0 {
+		ss := parseSections(cfgCli.ExcludeSections)
+		opts = append(opts, config.OptExcludeSections(ss))
+	}
+
 	if cfgCli.Format != "" {
 		cfgFormat, err := gnfmt.NewFormat(cfgCli.Format)
 		if err != nil {
@@ -336,6 +515,28 @@ func getOpts() {
 		opts = append(opts, config.OptFormat(cfgFormat))
 	}
 
+	if cfgCli.GraphFormat != "" {
+		f, err := graph.NewFormat(cfgCli.GraphFormat)
+		if err != nil {
+			slog.Warn("Cannot set graph format",
+				"format", cfgCli.GraphFormat, "error", err)
+		}
+		opts = append(opts, config.OptGraphFormat(f))
+	}
+
+	if cfgCli.GraphTokens > 0 {
+		opts = append(opts, config.OptGraphTokens(cfgCli.GraphTokens))
+	}
+
+	if cfgCli.GraphWindow != "" {
+		w, err := graph.NewWindow(cfgCli.GraphWindow)
+		if err != nil {
+			slog.Warn("Cannot set co-occurrence window",
+				"window", cfgCli.GraphWindow, "error", err)
+		}
+		opts = append(opts, config.OptGraphWindow(w))
+	}
+
 	if cfgCli.IncludeInputText {
 		opts = append(opts, config.OptIncludeInputText(cfgCli.IncludeInputText))
 	}
@@ -349,6 +550,27 @@ func getOpts() {
 		opts = append(opts, config.OptLanguage(l))
 	}
 
+	if cfgCli.Markup != "" {
+		m, err := markup.New(cfgCli.Markup)
+		if err != nil {
+			slog.Warn("Cannot set markup", "markup", cfgCli.Markup, "error", err)
+		}
+		opts = append(opts, config.OptMarkup(m))
+	}
+
+	if cfgCli.OffsetUnit != "" {
+		u, err := offset.New(cfgCli.OffsetUnit)
+		if err != nil {
+			slog.Warn("Cannot set offset unit",
+				"offsetUnit", cfgCli.OffsetUnit, "error", err)
+		}
+		opts = append(opts, config.OptOffsetUnit(u))
+	}
+
+	if cfgCli.PageMarker != "" {
+		opts = append(opts, config.OptPageMarker(cfgCli.PageMarker))
+	}
+
 	if cfgCli.TikaURL != "" {
 		opts = append(opts, config.OptTikaURL(cfgCli.TikaURL))
 	}
@@ -357,10 +579,36 @@ func getOpts() {
 		opts = append(opts, config.OptTokensAround(cfgCli.TokensAround))
 	}
 
+	if cfgCli.UnicodeForm != "" {
+		f, err := preprocess.New(cfgCli.UnicodeForm)
+		if err != nil {
+			slog.Warn("Cannot set normalization form",
+				"unicodeForm", cfgCli.UnicodeForm, "error", err)
+		}
+		opts = append(opts, config.OptUnicodeForm(f))
+	}
+
+	if cfgCli.UniqueNamesOrder != "" {
+		o, err := order.New(cfgCli.UniqueNamesOrder)
+		if err != nil {
+			slog.Warn("Cannot set order of unique names",
+				"order", cfgCli.UniqueNamesOrder, "error", err)
+		}
+		opts = append(opts, config.OptUniqueNamesOrder(o))
+	}
+
 	if cfgCli.VerifierURL != "" {
 		opts = append(opts, config.OptVerifierURL(cfgCli.VerifierURL))
 	}
 
+	if cfgCli.WithAggregatedNames {
+		opts = append(opts, config.OptWithAggregatedNames(true))
+	}
+
+	if cfgCli.WithAllCaps {
+		opts = append(opts, config.OptWithAllCaps(true))
+	}
+
 	if cfgCli.WithAllMatches {
 		opts = append(opts, config.OptWithAllMatches(true))
 	}
@@ -373,6 +621,26 @@ func getOpts() {
 		opts = append(opts, config.OptWithBayesOddsDetails(true))
 	}
 
+	if cfgCli.WithFuzzyMatch {
+		opts = append(opts, config.OptWithFuzzyMatch(true))
+	}
+
+	if cfgCli.WithHierarchy {
+		opts = append(opts, config.OptWithHierarchy(true))
+	}
+
+	if cfgCli.WithHistoricText {
+		opts = append(opts, config.OptWithHistoricText(true))
+	}
+
+	if cfgCli.WithLayoutCleanup {
+		opts = append(opts, config.OptWithLayoutCleanup(true))
+	}
+
+	if cfgCli.WithLinePositions {
+		opts = append(opts, config.OptWithLinePositions(true))
+	}
+
 	if cfgCli.WithPlainInput {
 		opts = append(opts, config.OptWithPlainInput(true))
 	}
@@ -385,6 +653,14 @@ func getOpts() {
 		opts = append(opts, config.OptWithOddsAdjustment(true))
 	}
 
+	if cfgCli.WithTables {
+		opts = append(opts, config.OptWithTables(true))
+	}
+
+	if cfgCli.WithTypeMaterial {
+		opts = append(opts, config.OptWithTypeMaterial(true))
+	}
+
 	if cfgCli.WithUniqueNames {
 		opts = append(opts, config.OptWithUniqueNames(true))
 	}
@@ -400,7 +676,7 @@ func getOpts() {
 }
 
 func findNames(
-	data string,
+	doc extract.Document,
 	cfg config.Config,
 	file string,
 	convDur float32,
@@ -417,7 +693,7 @@ func findNames(
 	}
 
 	gnf := gnfinder.New(cfg, dict, weights)
-	res := gnf.Find(file, data)
+	res := gnf.FindWithLayout(file, doc.Text, doc.Layout)
 	res.TextExtractionSec = convDur
 	if cfg.WithVerification {
 		sources := cfg.DataSources
@@ -427,9 +703,55 @@ func findNames(
 		res.MergeVerification(verifiedNames, stats, dur)
 	}
 	res.TotalSec = res.TextExtractionSec + res.NameFindingSec + res.NameVerifSec
+	if cfg.GraphFormat != graph.JSON && res.Graph != nil {
+		fmt.Println(res.Graph.Export(cfg.GraphFormat))
+		return
+	}
 	fmt.Println(res.Format(cfg.Format))
 }
 
+// getText converts a file or a URL content into UTF-8 encoded text.
+// Plain texts, HTML, DOCX, ODT, EPUB and PDF are converted locally, other
+// formats are sent to Apache Tika service. Marked-up texts are returned
+// as is.
+func getText(
+	input string,
+	cfg config.Config,
+) (extract.Document, float32, error) {
+	var doc extract.Document
+	ua := "gnfinder/" + gnfinder.Version + " (https://github.com/gnames/gnfinder)"
+	d := gndoc.New(cfg.TikaURL, gndoc.OptUserAgent(ua))
+	isURL := strings.HasPrefix(input, "http")
+	if cfg.WithPlainInput && !isURL {
+		txt, dur, err := d.TextFromFile(input, true)
+		doc.Text = txt
+		return doc, dur, err
+	}
+
+	start := time.Now()
+	var raw []byte
+	var err error
+	if isURL {
+		raw, err = extract.Download(input, ua, cfg.MaxFileSize)
+	} else {
+		raw, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return doc, 0, err
+	}
+
+	if cfg.Markup != markup.NoMarkup {
+		doc.Text = string(raw)
+	} else {
+		doc, err = extract.Extract(raw, cfg.MaxFileSize)
+		if errors.Is(err, extract.ErrUnsupported) {
+			doc.Text, err = d.GetText(bytes.NewReader(raw))
+		}
+	}
+	dur := float32(time.Since(start)) / float32(time.Second)
+	return doc, dur, err
+}
+
 func langStrings() string {
 	langs := lang.LangStrings()
 	return strings.Join(langs, ", ")
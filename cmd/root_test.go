@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/config"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/gnames/gnfinder/pkg/ent/order"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFlags checks that flags of the root command are initialized. Flags
+// with duplicate shorthands make pflag panic during initialization.
+func TestFlags(t *testing.T) {
+	assert := assert.New(t)
+	fs := rootCmd.Flags()
+	shorthands := make(map[string]string)
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Shorthand == "" {
+			return
+		}
+		assert.NotContains(shorthands, f.Shorthand, f.Name)
+		shorthands[f.Shorthand] = f.Name
+	})
+
+	f := fs.Lookup("aggregate-names")
+	assert.NotNil(f)
+	assert.Empty(f.Shorthand)
+	assert.Equal("utf8-input", fs.ShorthandLookup("U").Name)
+	assert.NotPanics(func() { _ = rootCmd.UsageString() })
+}
+
+// TestFlagsToConfig checks that flags of the command line are read into
+// the configuration. A flag that is read with a wrong type is ignored
+// silently, so it would not change the configuration.
+func TestFlagsToConfig(t *testing.T) {
+	assert := assert.New(t)
+	opts = nil
+	defer func() { opts = nil }()
+
+	err := rootCmd.ParseFlags([]string{
+		"--aggregate-names", "--all-caps", "--tables", "-z", "-T",
+		"-O", "bytes", "-S", "frequency",
+	})
+	assert.Nil(err)
+	aggregateFlag(rootCmd)
+	allCapsFlag(rootCmd)
+	tablesFlag(rootCmd)
+	fuzzyFlag(rootCmd)
+	typeMaterialFlag(rootCmd)
+	offsetUnitFlag(rootCmd)
+	sortFlag(rootCmd)
+
+	cfg := config.New(opts...)
+	assert.True(cfg.WithAggregatedNames)
+	assert.True(cfg.WithUniqueNames)
+	assert.True(cfg.WithAllCaps)
+	assert.True(cfg.WithTables)
+	assert.True(cfg.WithFuzzyMatch)
+	assert.True(cfg.WithTypeMaterial)
+	assert.Equal(offset.Byte, cfg.OffsetUnit)
+	assert.Equal(order.Frequency, cfg.UniqueNamesOrder)
+}
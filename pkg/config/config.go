@@ -2,23 +2,111 @@ package config
 
 import (
 	"log/slog"
+	"regexp"
 
+	"github.com/gnames/gnfinder/pkg/ent/checklist"
+	"github.com/gnames/gnfinder/pkg/ent/graph"
 	"github.com/gnames/gnfinder/pkg/ent/lang"
+	"github.com/gnames/gnfinder/pkg/ent/markup"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/gnames/gnfinder/pkg/ent/order"
+	"github.com/gnames/gnfinder/pkg/ent/preprocess"
+	"github.com/gnames/gnfinder/pkg/ent/section"
+	"github.com/gnames/gnfinder/pkg/ent/segment"
 	"github.com/gnames/gnfmt"
 )
 
+// defaultGraphTokens is the default distance in words between
+// co-occurring names.
+const defaultGraphTokens = 10
+
+// defaultContextSize is the default number of characters before and after
+// a name in the "characters" context mode.
+const defaultContextSize = 100
+
+// defaultItalicsOdds is the default odds ratio of italicized words of
+// marked-up texts.
+const defaultItalicsOdds = 10.0
+
+// maxTokensAround is the maximal number of tokens before and after a name.
+const maxTokensAround = 5
+
+// defaultMaxFileSize is the default limit of the size of downloaded and
+// unpacked documents in bytes.
+const defaultMaxFileSize = 100 << 20
+
 // Config is responsible for name-finding operations.
 type Config struct {
 	// BayesOddsThreshold sets the limit of posterior odds. Everything higher
 	// this limit will be classified as a name.
 	BayesOddsThreshold float64
 
+	// Checklist sets a mode for species lists and tables. In this mode
+	// every line or table cell is a separate document, names never
+	// continue from one cell to another, and prior odds of names are much
+	// higher than in running texts. Names get row and column numbers of
+	// their cells. Currently the following modes are supported:
+	//
+	// lines - every line is a cell
+	// csv - cells of comma-separated values
+	// tsv - cells of tab-separated values
+	Checklist checklist.Mode
+
+	// ChecklistColumn limits name-finding in CSV and TSV tables to one
+	// column. Columns start from 1, if ChecklistColumn is 0, all columns
+	// are used.
+	ChecklistColumn int
+
+	// ContextMode sets a way to find contexts of names. Names get their
+	// contexts with offsets of names inside of them. Sentences know
+	// taxonomic abbreviations like "sp.", "var.", "et al." and abbreviated
+	// genera. Currently the following modes are supported:
+	//
+	// words - TokensAround words before and after names (default)
+	// sentence - the sentence of a name
+	// paragraph - the paragraph of a name
+	// characters - ContextSize characters before and after a name
+	ContextMode segment.ContextMode
+
+	// ContextSize is the number of characters before and after a name for
+	// the "characters" context mode. Words cut by the limit are dropped.
+	ContextSize int
+
 	// Format output format for finding results. Possible formats are
 	// csv - CSV output
 	// compact - JSON in one line
 	// pretty - JSON with new lines and indentations.
 	Format gnfmt.Format
 
+	// GraphFormat is a format of the co-occurrence graph. JSON graph is
+	// a part of JSON output, GraphML and DOT formats replace the output of
+	// the command line application with the graph.
+	// Currently the following formats are supported:
+	//
+	// json - JSON (default)
+	// graphml - GraphML XML
+	// dot - DOT of Graphviz
+	GraphFormat graph.Format
+
+	// GraphTokens is the maximal distance in words between co-occurring
+	// names for the "tokens" window of the co-occurrence graph.
+	GraphTokens int
+
+	// GraphWindow sets a part of a text where names co-occur, and turns on
+	// the co-occurrence graph of found names. Nodes of the graph are
+	// unique names with their counts, edges are weighted by co-occurrence.
+	// Currently the following windows are supported:
+	//
+	// sentence - names of the same sentence
+	// paragraph - names of the same paragraph
+	// tokens - names separated by no more than GraphTokens words
+	GraphWindow graph.Window
+
+	// ExcludeSections are sections of a document, like references or
+	// acknowledgements, where names are not reported. Sections start with
+	// common English or German headings.
+	ExcludeSections []section.Section
+
 	// IncludeInputText can be set to true if the user wants to get back the text
 	// used for name-finding. This feature is epspecilly useful if original file
 	// was a PDF, MS Word, HTML etc. and a user wants to use OffsetStart and
@@ -30,6 +118,14 @@ type Config struct {
 	// options are ignored.
 	InputTextOnly bool
 
+	// ItalicsOdds is the odds ratio of italicized words of marked-up texts.
+	// Bayes' training data do not have italics, so posterior odds of
+	// italicized uninomials, specific and infraspecific epithets are
+	// multiplied by this fixed ratio. Odds details show it as "uniItalic:
+	// fixed", "spItalic: fixed" and "ispItalic: fixed". The ratio 1 makes
+	// Bayes ignore italics.
+	ItalicsOdds float64
+
 	// Language that is prevalent in the text. This setting helps to get
 	// a better result for NLP name-finding, because languages differ in their
 	// training patterns.
@@ -43,6 +139,57 @@ type Config struct {
 	// It is an empty string, if detection of language is not set.
 	LanguageDetected string
 
+	// Markup is a markup language of the input text. If it is set, tags
+	// or Markdown delimiters are removed before name-finding, italicized
+	// words are used as a sign of scientific names, and offsets of names
+	// refer to the original marked-up text.
+	// Currently the following markups are supported:
+	//
+	// html - HTML
+	// markdown - Markdown
+	// jats - JATS XML
+	Markup markup.Markup
+
+	// MaxFileSize is the maximal size in bytes of downloaded files and of
+	// files unpacked from DOCX, ODT and EPUB archives. Larger documents
+	// are rejected with an error instead of being read into memory.
+	MaxFileSize int64
+
+	// OffsetUnit sets units of start and end positions of names,
+	// annotations and relations. Currently the following units are
+	// supported:
+	//
+	// runes - Unicode code points, UTF-8 characters (default)
+	// bytes - bytes of UTF-8 encoded text
+	// utf16 - UTF-16 code units, used by JavaScript and Java strings
+	OffsetUnit offset.Unit
+
+	// PageMarker is a regular expression that matches lines separating
+	// pages of texts without form feeds, for example OCR dumps with lines
+	// like "--- Page 12 ---". The layout cleanup removes such lines and
+	// uses them to find running headers, footers and page numbers.
+	PageMarker string
+
+	// UnicodeForm is a Unicode normalization form applied to the text
+	// before name-finding. Zero-width characters and soft hyphens are
+	// removed, and all spaces become usual spaces. Offsets of names still
+	// refer to the original text. Currently the following forms are
+	// supported:
+	//
+	// nfc - canonical composition (default)
+	// nfkc - compatibility composition, also changes ligatures and
+	//        full-width letters
+	// none - no normalization
+	UnicodeForm preprocess.Form
+
+	// UniqueNamesOrder sets sorting of unique names. Currently the
+	// following orders are supported:
+	//
+	// alphabetical - by normalized names (default)
+	// frequency - by the number of occurrences, the most frequent first
+	// appearance - by the first occurrence in the text
+	UniqueNamesOrder order.Order
+
 	// DataSources is a list of data-source IDs used for the
 	// name-verification. These data-sources will always be matched with the
 	// verified names. You can find the list of all data-sources at
@@ -54,12 +201,25 @@ type Config struct {
 	TikaURL string
 
 	// TokensAround sets the number of tokens (words) before and after each
-	// name-candidate. These words will be returned with the output.
+	// name-candidate. These words will be returned with the output. The
+	// acceptable range is from 0 to 5.
 	TokensAround int
 
 	// VerifierURL contains the URL of a name-verification service.
 	VerifierURL string
 
+	// WithAggregatedNames can be set to true to get a unique list of names,
+	// where every name has the number and offsets of its occurrences,
+	// its verbatim variants, and the maximal odds and cardinality of its
+	// occurrences. It turns on WithUniqueNames.
+	WithAggregatedNames bool
+
+	// WithAllCaps can be set to true to find names written in upper case,
+	// for example "PARDOSA MOESTA" in headings and table headers. Known
+	// specific epithets and ranks of upper-case runs are converted to low
+	// case, and verbatim names keep their original form.
+	WithAllCaps bool
+
 	// WithAllMatches sets verification to return all found matches.
 	WithAllMatches bool
 
@@ -75,6 +235,34 @@ type Config struct {
 	// WithBayesOddsDetails show in detail how odds are calculated.
 	WithBayesOddsDetails bool
 
+	// WithFuzzyMatch can be set to true to match words that are one edit
+	// away from known genera, uninomials and specific epithets. It helps to
+	// find misspelled names and to suggest their correct spelling.
+	WithFuzzyMatch bool
+
+	// WithHistoricText can be set to true for old texts, for example OCR
+	// scans from the Biodiversity Heritage Library. In this mode old letters
+	// (ſ), ligatures (æ, œ, ﬁ) and broken diacritics are normalized, and
+	// common OCR errors ("rn" read as "m", "li" read as "h") are corrected
+	// using the dictionaries.
+	WithHistoricText bool
+
+	// WithHierarchy can be set to true to recognize names that are
+	// headings of a document, for example "Family Lycosidae" or "Pardosa
+	// moesta Banks, 1892", and to assemble a tree of their taxa. Other names
+	// are attributed to the taxon of the enclosing heading.
+	WithHierarchy bool
+
+	// WithLayoutCleanup can be set to true for texts extracted from paged
+	// documents. It removes page numbers, running headers and footers, and
+	// hyphens at the ends of lines that split known genera and epithets.
+	// Offsets of names still refer to the original text.
+	WithLayoutCleanup bool
+
+	// WithLinePositions can be set to true to receive line and column
+	// numbers of start and end of names in addition to offsets.
+	WithLinePositions bool
+
 	// WithOddsAdjustment can be set to true to adjust calculated odds using the
 	// ratio of scientific names found in text to the number of capitalized
 	// words.
@@ -87,8 +275,23 @@ type Config struct {
 
 	// WithPositionInBytes can be set to true to receive offsets in number of
 	// bytes instead of UTF-8 characters.
+	//
+	// Deprecated: use OffsetUnit instead. If it is true, and OffsetUnit is
+	// not set by OptOffsetUnit, offsets are given in bytes.
 	WithPositionInBytes bool
 
+	// WithTables can be set to true to detect tables flattened into running
+	// texts, for example tables of taxa extracted from PDF files. Tables are
+	// lines with the same number of columns separated by tabs, pipes or
+	// runs of spaces. Names found in tables are flagged, and they have
+	// higher prior odds than names of running texts.
+	WithTables bool
+
+	// WithTypeMaterial can be set to true to extract type designations,
+	// examined material and specimen codes that follow names with
+	// nomenclatural annotations.
+	WithTypeMaterial bool
+
 	// WithUniqueNames can be set to true to get a unique list of names.
 	WithUniqueNames bool
 
@@ -97,6 +300,21 @@ type Config struct {
 
 	// APIDoc
 	APIDoc string
+
+	// offsetUnitSet is true if OffsetUnit was set by OptOffsetUnit. Then
+	// the deprecated WithPositionInBytes does not change it.
+	offsetUnitSet bool
+}
+
+// EffectiveOffsetUnit returns the unit of offsets. The deprecated
+// WithPositionInBytes field might be set without options, then offsets are
+// given in bytes, unless OffsetUnit was set explicitly.
+func (cfg Config) EffectiveOffsetUnit() offset.Unit {
+	if cfg.WithPositionInBytes && cfg.OffsetUnit == offset.Rune &&
+		!cfg.offsetUnitSet {
+		return offset.Byte
+	}
+	return cfg.OffsetUnit
 }
 
 // Option type for changing GNfinder settings.
@@ -111,6 +329,52 @@ func OptBayesOddsThreshold(f float64) Option {
 	}
 }
 
+// OptChecklist sets a mode for species lists and tables.
+func OptChecklist(m checklist.Mode) Option {
+	return func(cfg *Config) {
+		cfg.Checklist = m
+	}
+}
+
+// OptChecklistColumn sets a column of a table for name-finding.
+func OptChecklistColumn(i int) Option {
+	return func(cfg *Config) {
+		if i < 0 {
+			slog.Warn("Checklist column cannot be negative, using all columns")
+			i = 0
+		}
+		cfg.ChecklistColumn = i
+	}
+}
+
+// OptContextMode sets a way to find contexts of names.
+func OptContextMode(m segment.ContextMode) Option {
+	return func(cfg *Config) {
+		cfg.ContextMode = m
+	}
+}
+
+// OptContextSize sets the number of characters before and after a name
+// for the "characters" context mode.
+func OptContextSize(i int) Option {
+	return func(cfg *Config) {
+		if i < 1 {
+			slog.Warn("Context size must be positive, using default",
+				"size", defaultContextSize)
+			i = defaultContextSize
+		}
+		cfg.ContextSize = i
+	}
+}
+
+// OptExcludeSections sets sections of a document where names are not
+// reported.
+func OptExcludeSections(ss []section.Section) Option {
+	return func(cfg *Config) {
+		cfg.ExcludeSections = ss
+	}
+}
+
 // OptFormat sets output format
 func OptFormat(f gnfmt.Format) Option {
 	return func(cnf *Config) {
@@ -118,6 +382,33 @@ func OptFormat(f gnfmt.Format) Option {
 	}
 }
 
+// OptGraphFormat sets a format of the co-occurrence graph.
+func OptGraphFormat(f graph.Format) Option {
+	return func(cfg *Config) {
+		cfg.GraphFormat = f
+	}
+}
+
+// OptGraphTokens sets the maximal distance in words between co-occurring
+// names.
+func OptGraphTokens(i int) Option {
+	return func(cfg *Config) {
+		if i < 1 {
+			slog.Warn("Co-occurrence distance must be positive, using default",
+				"tokens", defaultGraphTokens)
+			i = defaultGraphTokens
+		}
+		cfg.GraphTokens = i
+	}
+}
+
+// OptGraphWindow sets a part of a text where names co-occur.
+func OptGraphWindow(w graph.Window) Option {
+	return func(cfg *Config) {
+		cfg.GraphWindow = w
+	}
+}
+
 // OptIncludeInputText indicates if to return original UTF8-encoded input.
 func OptIncludeInputText(b bool) Option {
 	return func(cfg *Config) {
@@ -132,6 +423,18 @@ func OptInputTextOnly(b bool) Option {
 	}
 }
 
+// OptItalicsOdds sets the odds ratio of italicized words.
+func OptItalicsOdds(f float64) Option {
+	return func(cfg *Config) {
+		if f < 1 {
+			slog.Warn("Italics odds cannot be less than 1, using default",
+				"odds", defaultItalicsOdds)
+			f = defaultItalicsOdds
+		}
+		cfg.ItalicsOdds = f
+	}
+}
+
 // OptLanguage sets a language of a text.
 func OptLanguage(l lang.Language) Option {
 	return func(cfg *Config) {
@@ -139,6 +442,45 @@ func OptLanguage(l lang.Language) Option {
 	}
 }
 
+// OptMarkup sets a markup language of a text.
+func OptMarkup(m markup.Markup) Option {
+	return func(cfg *Config) {
+		cfg.Markup = m
+	}
+}
+
+// OptMaxFileSize sets the maximal size of downloaded and unpacked files.
+func OptMaxFileSize(i int64) Option {
+	return func(cfg *Config) {
+		if i <= 0 {
+			slog.Warn("Max file size must be positive, using default",
+				"size", defaultMaxFileSize)
+			i = defaultMaxFileSize
+		}
+		cfg.MaxFileSize = i
+	}
+}
+
+// OptOffsetUnit sets units of offsets in the output.
+func OptOffsetUnit(u offset.Unit) Option {
+	return func(cfg *Config) {
+		cfg.OffsetUnit = u
+		cfg.offsetUnitSet = true
+	}
+}
+
+// OptPageMarker sets a regular expression for lines that separate pages.
+// Invalid regular expressions are ignored.
+func OptPageMarker(s string) Option {
+	return func(cfg *Config) {
+		if _, err := regexp.Compile(s); err != nil {
+			slog.Warn("Cannot use page marker", "marker", s, "error", err)
+			return
+		}
+		cfg.PageMarker = s
+	}
+}
+
 // OptDataSources sets data sources that will always be checked
 // during verification process.
 func OptDataSources(is []int) Option {
@@ -162,14 +504,28 @@ func OptTokensAround(i int) Option {
 			slog.Warn("Tokens' number around name must be in between 0 and 5")
 			i = 0
 		}
-		if i > 5 {
+		if i > maxTokensAround {
 			slog.Warn("Tokens' number around name must be in between 0 and 5")
-			i = 5
+			i = maxTokensAround
 		}
 		cfg.TokensAround = i
 	}
 }
 
+// OptUnicodeForm sets a Unicode normalization form of a text.
+func OptUnicodeForm(f preprocess.Form) Option {
+	return func(cfg *Config) {
+		cfg.UnicodeForm = f
+	}
+}
+
+// OptUniqueNamesOrder sets sorting of unique names.
+func OptUniqueNamesOrder(o order.Order) Option {
+	return func(cfg *Config) {
+		cfg.UniqueNamesOrder = o
+	}
+}
+
 // OptVerifierURL sets URL for verification service.
 func OptVerifierURL(s string) Option {
 	return func(cfg *Config) {
@@ -185,6 +541,21 @@ func OptWithAllMatches(b bool) Option {
 	}
 }
 
+// OptWithAggregatedNames is an option to aggregate occurrences of unique
+// names.
+func OptWithAggregatedNames(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithAggregatedNames = b
+	}
+}
+
+// OptWithAllCaps sets detection of names written in upper case.
+func OptWithAllCaps(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithAllCaps = b
+	}
+}
+
 // OptWithAmbiguousNames sets WithAmbiguousNames option to show ambiguous
 // uninomials and genera.
 func OptWithAmbiguousNames(b bool) Option {
@@ -208,6 +579,22 @@ func OptWithBayesOddsDetails(b bool) Option {
 	}
 }
 
+// OptWithFuzzyMatch sets fuzzy matching of misspelled words to the
+// dictionaries.
+func OptWithFuzzyMatch(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithFuzzyMatch = b
+	}
+}
+
+// OptWithHistoricText sets normalization of historic texts and
+// correction of OCR errors.
+func OptWithHistoricText(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithHistoricText = b
+	}
+}
+
 // OptWithOddsAdjustment is an option that triggers recalculation of prior odds
 // using number of found names divided by number of all name candidates.
 func OptWithOddsAdjustment(b bool) Option {
@@ -216,6 +603,30 @@ func OptWithOddsAdjustment(b bool) Option {
 	}
 }
 
+// OptWithHierarchy is an option to assemble a tree of taxa from headings
+// of a document.
+func OptWithHierarchy(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithHierarchy = b
+	}
+}
+
+// OptWithLayoutCleanup is an option to remove page numbers, running
+// headers and footers, and hyphenation of known words.
+func OptWithLayoutCleanup(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithLayoutCleanup = b
+	}
+}
+
+// OptWithLinePositions is an option to add line and column numbers of
+// names to the output.
+func OptWithLinePositions(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithLinePositions = b
+	}
+}
+
 // OptWithPlainInput sets WithPlainInput option indicating there is no need
 // to check file type and encoding, and the file can be read directly.
 func OptWithPlainInput(b bool) Option {
@@ -226,9 +637,35 @@ func OptWithPlainInput(b bool) Option {
 
 // OptWithPositonInBytes is an option that allows to have offsets in number of
 // bytes of number of UTF-8 characters.
+//
+// Deprecated: use OptOffsetUnit instead. It is ignored if OptOffsetUnit
+// is also given.
 func OptWithPositonInBytes(b bool) Option {
 	return func(cfg *Config) {
 		cfg.WithPositionInBytes = b
+		if cfg.offsetUnitSet {
+			return
+		}
+		if b {
+			cfg.OffsetUnit = offset.Byte
+		} else if cfg.OffsetUnit == offset.Byte {
+			cfg.OffsetUnit = offset.Rune
+		}
+	}
+}
+
+// OptWithTables sets detection of tables in running texts.
+func OptWithTables(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithTables = b
+	}
+}
+
+// OptWithTypeMaterial indicates if to extract type material and specimen
+// codes for names with nomenclatural annotations.
+func OptWithTypeMaterial(b bool) Option {
+	return func(cfg *Config) {
+		cfg.WithTypeMaterial = b
 	}
 }
 
@@ -256,7 +693,11 @@ func New(opts ...Option) Config {
 		Language:           lang.English,
 		WithBayes:          true,
 		BayesOddsThreshold: 80.0,
+		ItalicsOdds:        defaultItalicsOdds,
 		TokensAround:       0,
+		GraphTokens:        defaultGraphTokens,
+		ContextSize:        defaultContextSize,
+		MaxFileSize:        defaultMaxFileSize,
 		VerifierURL:        "https://verifier.globalnames.org/api/v1/",
 		TikaURL:            "https://tika.globalnames.org",
 		APIDoc:             "https://apidoc.globalnames.org/gnfinder",
@@ -269,5 +710,13 @@ func New(opts ...Option) Config {
 	if len(cfg.DataSources) > 0 {
 		cfg.WithVerification = true
 	}
+
+	if cfg.WithAggregatedNames {
+		cfg.WithUniqueNames = true
+	}
+
+	if cfg.GraphFormat != graph.JSON && cfg.GraphWindow == graph.NoGraph {
+		cfg.GraphWindow = graph.Sentence
+	}
 	return cfg
 }
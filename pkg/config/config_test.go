@@ -7,6 +7,7 @@ import (
 
 	"github.com/gnames/gnfinder/pkg/config"
 	"github.com/gnames/gnfinder/pkg/ent/lang"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,7 +20,7 @@ func TestConfig(t *testing.T) {
 		assert.Equal(t, cfg.LanguageDetected, "")
 		assert.Equal(t, cfg.TokensAround, 0)
 		assert.True(t, cfg.WithBayes)
-		assert.False(t, cfg.WithPositionInBytes)
+		assert.Equal(t, cfg.OffsetUnit, offset.Rune)
 	})
 
 	t.Run("takes language", func(t *testing.T) {
@@ -35,7 +36,37 @@ func TestConfig(t *testing.T) {
 
 	t.Run("sets offsets in bytes", func(t *testing.T) {
 		cfg := config.New(config.OptWithPositonInBytes(true))
-		assert.True(t, cfg.WithPositionInBytes)
+		assert.Equal(t, cfg.OffsetUnit, offset.Byte)
+	})
+
+	t.Run("sets offset unit", func(t *testing.T) {
+		cfg := config.New(config.OptOffsetUnit(offset.UTF16))
+		assert.Equal(t, cfg.OffsetUnit, offset.UTF16)
+		cfg = config.New(
+			config.OptOffsetUnit(offset.UTF16),
+			config.OptWithPositonInBytes(false),
+		)
+		assert.Equal(t, cfg.OffsetUnit, offset.UTF16)
+	})
+
+	t.Run("explicit offset unit wins over deprecated bytes", func(t *testing.T) {
+		cfg := config.New(
+			config.OptOffsetUnit(offset.Rune),
+			config.OptWithPositonInBytes(true),
+		)
+		assert.Equal(t, cfg.OffsetUnit, offset.Rune)
+		assert.Equal(t, cfg.EffectiveOffsetUnit(), offset.Rune)
+
+		cfg = config.New(
+			config.OptWithPositonInBytes(true),
+			config.OptOffsetUnit(offset.Rune),
+		)
+		assert.Equal(t, cfg.OffsetUnit, offset.Rune)
+		assert.Equal(t, cfg.EffectiveOffsetUnit(), offset.Rune)
+
+		cfg = config.New()
+		cfg.WithPositionInBytes = true
+		assert.Equal(t, cfg.EffectiveOffsetUnit(), offset.Byte)
 	})
 
 	t.Run("sets tokens number", func(t *testing.T) {
@@ -55,6 +86,24 @@ func TestConfig(t *testing.T) {
 		assert.Equal(t, cfg.BayesOddsThreshold, 200.0)
 	})
 
+	t.Run("sets italics odds", func(t *testing.T) {
+		cfg := config.New()
+		assert.Equal(t, cfg.ItalicsOdds, 10.0)
+		cfg = config.New(config.OptItalicsOdds(3))
+		assert.Equal(t, cfg.ItalicsOdds, 3.0)
+		cfg = config.New(config.OptItalicsOdds(0.5))
+		assert.Equal(t, cfg.ItalicsOdds, 10.0)
+	})
+
+	t.Run("sets max file size", func(t *testing.T) {
+		cfg := config.New()
+		assert.Equal(t, cfg.MaxFileSize, int64(100<<20))
+		cfg = config.New(config.OptMaxFileSize(1024))
+		assert.Equal(t, cfg.MaxFileSize, int64(1024))
+		cfg = config.New(config.OptMaxFileSize(-1))
+		assert.Equal(t, cfg.MaxFileSize, int64(100<<20))
+	})
+
 	t.Run("sets several options", func(t *testing.T) {
 		opts := []config.Option{
 			config.OptWithBayes(true),
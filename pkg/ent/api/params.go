@@ -14,14 +14,71 @@ type FinderParams struct {
 	Format string `json:"format" form:"format"`
 
 	// BytesOffset changes offset value from UTF-8 characters to bytes number.
+	// It is ignored if OffsetUnit is set.
 	BytesOffset bool `json:"bytesOffset" form:"bytesOffset"`
 
+	// OffsetUnit sets units of offsets: "runes" (UTF-8 characters, default),
+	// "bytes" or "utf16" (UTF-16 code units of JavaScript strings).
+	OffsetUnit string `json:"offsetUnit" form:"offsetUnit"`
+
+	// Markup is a markup language of the text, the uploaded file or the
+	// downloaded page: "html", "markdown" or "jats". Marked-up documents
+	// are not converted to plain text, so italics help to find names, and
+	// offsets refer to the original document.
+	Markup string `json:"markup" form:"markup"`
+
+	// LinePositions adds line and column numbers of names to the result.
+	LinePositions bool `json:"linePositions" form:"linePositions"`
+
+	// Checklist treats every line ("lines") or every table cell ("csv",
+	// "tsv") of the text as a separate document.
+	Checklist string `json:"checklist" form:"checklist"`
+
+	// ChecklistColumn limits name-finding in tables to one column,
+	// columns start from 1.
+	ChecklistColumn int `json:"checklistColumn" form:"checklistColumn"`
+
+	// Context sets a context of names: "words" (default), "sentence",
+	// "paragraph" or "characters".
+	Context string `json:"context" form:"context"`
+
+	// ContextSize is the number of characters before and after a name for
+	// the "characters" context.
+	ContextSize int `json:"contextSize" form:"contextSize"`
+
+	// ExcludeSections are sections of a document, like "references" or
+	// "acknowledgements", where names are not reported.
+	ExcludeSections []string `json:"excludeSections" form:"excludeSections[]"`
+
+	// Graph sets a window of the co-occurrence graph of names:
+	// "sentence", "paragraph" or "tokens".
+	Graph string `json:"graph" form:"graph"`
+
+	// GraphTokens is the maximal distance in words between co-occurring
+	// names of the "tokens" window.
+	GraphTokens int `json:"graphTokens" form:"graphTokens"`
+
+	// Hierarchy builds a tree of taxa from headings of a document.
+	Hierarchy bool `json:"hierarchy" form:"hierarchy"`
+
+	// Tables detects tables in running texts and flags names found in
+	// them.
+	Tables bool `json:"tables" form:"tables"`
+
 	// ReturnContent adds input text to the JSON result.
 	ReturnContent bool `json:"returnContent" form:"returnContent"`
 
 	// UniqueNames sets flag for JSON output to return only unique names.
 	UniqueNames bool `json:"unique" form:"unique"`
 
+	// AggregatedNames returns unique names with counts, offsets and
+	// verbatim variants of all their occurrences.
+	AggregatedNames bool `json:"aggregatedNames" form:"aggregatedNames"`
+
+	// UniqueNamesOrder sets sorting of unique names: "alphabetical"
+	// (default), "frequency" or "appearance".
+	UniqueNamesOrder string `json:"uniqueNamesOrder" form:"uniqueNamesOrder"`
+
 	// AmbiguousNames preserves detected ambigous uninomials like `America`
 	// or `Cancer`.
 	AmbiguousNames bool `json:"ambiguousNames" form:"ambiguousNames"`
@@ -0,0 +1,157 @@
+// Package checklist splits checklists and tables into cells. Lines of
+// species lists and cells of spreadsheet columns are independent documents
+// for name-finding, so names never continue from one cell to another. It
+// also finds tables flattened into lines of running texts.
+package checklist
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Mode is a way to split a checklist or a table into cells.
+type Mode int
+
+// Supported modes.
+const (
+	NoChecklist Mode = iota
+	Lines
+	CSV
+	TSV
+)
+
+var modeStrings = [...]string{"", "lines", "csv", "tsv"}
+
+// String representation of a Mode.
+func (m Mode) String() string {
+	return modeStrings[m]
+}
+
+// New takes a string and returns a matching mode. If the string is
+// unknown, it returns NoChecklist and an error.
+func New(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return NoChecklist, nil
+	case "lines", "txt":
+		return Lines, nil
+	case "csv":
+		return CSV, nil
+	case "tsv":
+		return TSV, nil
+	}
+	return NoChecklist, fmt.Errorf("unknown checklist mode %s", s)
+}
+
+// ModeStrings returns string representations of supported modes.
+func ModeStrings() []string {
+	res := slices.Clone(modeStrings[1:])
+	slices.Sort(res)
+	return res
+}
+
+// Cell is a line of a checklist or a cell of a table.
+type Cell struct {
+	// Row is the number of the line or of the table row. It starts from 1.
+	Row int
+
+	// Column is the number of the table column. It starts from 1, and it
+	// is 0 for lines of a checklist.
+	Column int
+
+	// Start of the cell content in the text.
+	Start int
+
+	// End of the cell content in the text.
+	End int
+}
+
+// Cells splits a text into cells. If column is larger than 0, only cells
+// of this column are returned for tables. Cells of quoted fields do not
+// include quotes. Empty cells are skipped.
+func Cells(text []rune, m Mode, column int) []Cell {
+	switch m {
+	case Lines:
+		return lineCells(text)
+	case CSV:
+		return tableCells(text, ',', column)
+	case TSV:
+		return tableCells(text, '\t', column)
+	}
+	return nil
+}
+
+func lineCells(text []rune) []Cell {
+	var res []Cell
+	row, start := 1, 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && text[i] != '\n' {
+			continue
+		}
+		if c := newCell(text, row, 0, start, i); !isEmpty(text, c) {
+			res = append(res, c)
+		}
+		row++
+		start = i + 1
+	}
+	return res
+}
+
+func tableCells(text []rune, sep rune, column int) []Cell {
+	var res []Cell
+	row, col := 1, 1
+	for i := 0; i <= len(text); i++ {
+		start, end := i, i
+		quoted := i < len(text) && text[i] == '"'
+		if quoted {
+			start, end = i+1, quoteEnd(text, i+1)
+			i = end
+		}
+		for i < len(text) && text[i] != sep && text[i] != '\n' {
+			i++
+		}
+		if !quoted {
+			end = i
+		}
+
+		c := newCell(text, row, col, start, end)
+		if (column == 0 || column == col) && !isEmpty(text, c) {
+			res = append(res, c)
+		}
+		if i < len(text) && text[i] == sep {
+			col++
+		} else {
+			row, col = row+1, 1
+		}
+	}
+	return res
+}
+
+// quoteEnd returns the position of the quote that closes a quoted field.
+// Doubled quotes inside of the field do not close it.
+func quoteEnd(text []rune, i int) int {
+	for i < len(text) {
+		if text[i] != '"' {
+			i++
+			continue
+		}
+		if i+1 < len(text) && text[i+1] == '"' {
+			i += 2
+			continue
+		}
+		return i
+	}
+	return i
+}
+
+func newCell(text []rune, row, col, start, end int) Cell {
+	if end > start && text[end-1] == '\r' {
+		end--
+	}
+	return Cell{Row: row, Column: col, Start: start, End: end}
+}
+
+func isEmpty(text []rune, c Cell) bool {
+	return strings.TrimSpace(string(text[c.Start:c.End])) == ""
+}
@@ -0,0 +1,75 @@
+package checklist_test
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/checklist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		s    string
+		mode checklist.Mode
+		err  bool
+	}{
+		{"", checklist.NoChecklist, false},
+		{"lines", checklist.Lines, false},
+		{"CSV", checklist.CSV, false},
+		{"tsv", checklist.TSV, false},
+		{"xlsx", checklist.NoChecklist, true},
+	}
+	for _, v := range tests {
+		m, err := checklist.New(v.s)
+		assert.Equal(v.mode, m, v.s)
+		assert.Equal(v.err, err != nil, v.s)
+	}
+	assert.Equal([]string{"csv", "lines", "tsv"}, checklist.ModeStrings())
+}
+
+func TestCells(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, text string
+		mode      checklist.Mode
+		column    int
+		cells     []string
+		rows      []int
+		columns   []int
+	}{
+		{
+			"lines", "Aus bus\r\n\nPardosa moesta\n", checklist.Lines, 0,
+			[]string{"Aus bus", "Pardosa moesta"}, []int{1, 3}, []int{0, 0},
+		},
+		{
+			"csv", "id,name\n1,Aus bus\n2,\"Pardosa moesta, 1902\"", checklist.CSV,
+			0, []string{"id", "name", "1", "Aus bus", "2", "Pardosa moesta, 1902"},
+			[]int{1, 1, 2, 2, 3, 3}, []int{1, 2, 1, 2, 1, 2},
+		},
+		{
+			"csv column", "id,name\n1,Aus bus\n2,\"Pardosa\nmoesta\"\n3,", checklist.CSV,
+			2, []string{"name", "Aus bus", "Pardosa\nmoesta"},
+			[]int{1, 2, 3}, []int{2, 2, 2},
+		},
+		{
+			"tsv", "Aus bus\tnote\n\tBubo bubo", checklist.TSV, 0,
+			[]string{"Aus bus", "note", "Bubo bubo"}, []int{1, 1, 2}, []int{1, 2, 2},
+		},
+		{"none", "Aus bus", checklist.NoChecklist, 0, nil, nil, nil},
+	}
+	for _, v := range tests {
+		text := []rune(v.text)
+		res := checklist.Cells(text, v.mode, v.column)
+		var cells []string
+		var rows, columns []int
+		for _, c := range res {
+			cells = append(cells, string(text[c.Start:c.End]))
+			rows = append(rows, c.Row)
+			columns = append(columns, c.Column)
+		}
+		assert.Equal(v.cells, cells, v.msg)
+		assert.Equal(v.rows, rows, v.msg)
+		assert.Equal(v.columns, columns, v.msg)
+	}
+}
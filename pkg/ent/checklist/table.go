@@ -0,0 +1,122 @@
+package checklist
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minTableRows is the minimal number of lines of a table region.
+const minTableRows = 3
+
+// maxRowWords is the maximal number of words in a line of a table.
+const maxRowWords = 20
+
+// row keeps structure of a line that might belong to a table.
+type row struct {
+	start, end int
+
+	// columns is the number of columns separated by tabs, pipes or by
+	// several spaces.
+	columns int
+}
+
+// Tables finds regions of a running text that look like flattened tables,
+// for example tables of taxa extracted from PDF files. Tables are
+// consecutive lines with the same number of columns separated by tabs,
+// pipes or by several spaces. It returns start and end positions of the
+// regions.
+func Tables(text []rune) [][2]int {
+	var res [][2]int
+	var run []row
+	flush := func() {
+		if len(run) >= minTableRows {
+			res = append(res, [2]int{run[0].start, run[len(run)-1].end})
+		}
+		run = nil
+	}
+	for _, r := range rows(text) {
+		if r.columns < 2 {
+			flush()
+			continue
+		}
+		if len(run) > 0 && !sameStructure(run[len(run)-1], r) {
+			flush()
+		}
+		run = append(run, r)
+	}
+	flush()
+	return res
+}
+
+// rows splits a text into lines and finds their structure.
+func rows(text []rune) []row {
+	var res []row
+	start := 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && text[i] != '\n' && text[i] != '\f' {
+			continue
+		}
+		res = append(res, newRow(text, start, i))
+		start = i + 1
+	}
+	return res
+}
+
+func newRow(text []rune, start, end int) row {
+	r := row{start: start, end: end}
+	line := string(text[start:end])
+	words := strings.Fields(line)
+	if len(words) == 0 || len(words) > maxRowWords {
+		return r
+	}
+	r.columns = columnsNum(line)
+	return r
+}
+
+// sameStructure checks if two lines might be rows of the same table: they
+// have the same number of columns.
+func sameStructure(r1, r2 row) bool {
+	return r1.columns == r2.columns
+}
+
+// columnsNum counts parts of a line separated by tabs, pipes or by two or
+// more spaces. Prose often has two spaces after a period, so two spaces
+// between the end of a sentence and a capitalized word do not separate
+// columns.
+func columnsNum(line string) int {
+	rs := []rune(strings.Trim(line, " \t|"))
+	if len(rs) == 0 {
+		return 0
+	}
+	res := 1
+	var spaces int
+	var isSep bool
+	for i, r := range rs {
+		switch r {
+		case '\t', '|':
+			isSep = true
+		case ' ':
+			spaces++
+		default:
+			if isSep || (spaces > 1 && !isSentenceEnd(rs, i-spaces-1, r)) {
+				res++
+			}
+			spaces = 0
+			isSep = false
+		}
+	}
+	return res
+}
+
+// isSentenceEnd checks if a character at the index i ends a sentence, and
+// the next word starts with a capital letter.
+func isSentenceEnd(rs []rune, i int, next rune) bool {
+	if i < 0 || !unicode.IsUpper(next) {
+		return false
+	}
+	switch rs[i] {
+	case '.', '!', '?':
+		return true
+	}
+	return false
+}
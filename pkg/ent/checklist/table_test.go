@@ -0,0 +1,82 @@
+package checklist_test
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/checklist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTables(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, text string
+		tables    []string
+	}{
+		{
+			"tabs",
+			"We collected spiders in 1902 and in 1903 at two sites.\n" +
+				"Pardosa moesta\t12\t3.4\n" +
+				"Bubo bubo\t5\t1.2\n" +
+				"Aus bus\t7\t0.5\n" +
+				"All of them were common.",
+			[]string{"Pardosa moesta\t12\t3.4\nBubo bubo\t5\t1.2\n" +
+				"Aus bus\t7\t0.5"},
+		},
+		{
+			"pipes",
+			"| Species | Site |\n| Pardosa moesta | Ohio |\n" +
+				"| Bubo bubo | Utah |\n",
+			[]string{"| Species | Site |\n| Pardosa moesta | Ohio |\n" +
+				"| Bubo bubo | Utah |"},
+		},
+		{
+			"numbers without columns",
+			"Pardosa moesta 12 3.4\n" +
+				"Bubo bubo 5 1.2\n" +
+				"Aus bus 7 0.5\n",
+			nil,
+		},
+		{
+			"references",
+			"References\n" +
+				"Banks N. 1892. The spider fauna of Ohio. Proc. Acad. 12: 1-81.\n" +
+				"Emerton J. 1885. New England Lycosidae. Trans. Conn. 6: 481-505.\n" +
+				"Gertsch W. 1934. Notes on American Lycosidae. Am. Mus. Novit. 693.\n" +
+				"Kaston B. 1948. Spiders of Connecticut. Bull. Conn. 70: 1-874.\n",
+			nil,
+		},
+		{
+			"columns",
+			"Species     Site     Habitat\n" +
+				"Pardosa moesta     Ohio     meadow\n" +
+				"Bubo bubo     Utah     forest\n",
+			[]string{"Species     Site     Habitat\n" +
+				"Pardosa moesta     Ohio     meadow\n" +
+				"Bubo bubo     Utah     forest"},
+		},
+		{
+			"different structure",
+			"Pardosa moesta\t12\t3.4\n" +
+				"Bubo bubo\t5\n" +
+				"Aus bus\t7\t0.5\t1\n",
+			nil,
+		},
+		{
+			"prose",
+			"Spiders were collected.  The specimens were\n" +
+				"deposited in the museum.  Most of them were\n" +
+				"adult females.  Some were juveniles.\n",
+			nil,
+		},
+		{"too short", "Pardosa moesta\t12\nBubo bubo\t5\n", nil},
+	}
+	for _, v := range tests {
+		text := []rune(v.text)
+		var tables []string
+		for _, tbl := range checklist.Tables(text) {
+			tables = append(tables, string(text[tbl[0]:tbl[1]]))
+		}
+		assert.Equal(v.tables, tables, v.msg)
+	}
+}
@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Export returns the graph in GraphML or DOT format. JSON graphs are
+// exported as a part of the name-finding output, so for JSON the function
+// returns an empty string.
+func (g *Graph) Export(f Format) string {
+	switch f {
+	case GraphML:
+		return g.GraphML()
+	case DOT:
+		return g.DOT()
+	}
+	return ""
+}
+
+// GraphML returns the graph as an undirected GraphML document. Nodes have
+// "name" and "count" attributes, edges have "weight" attribute.
+func (g *Graph) GraphML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	b.WriteString(`  <key id="name" for="node" attr.name="name" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="count" for="node" attr.name="count" attr.type="int"/>` + "\n")
+	b.WriteString(`  <key id="weight" for="edge" attr.name="weight" attr.type="int"/>` + "\n")
+	b.WriteString(`  <graph id="cooccurrence" edgedefault="undirected">` + "\n")
+	for _, v := range g.Nodes {
+		fmt.Fprintf(&b, "    <node id=\"n%d\">\n", v.ID)
+		b.WriteString(`      <data key="name">`)
+		_ = xml.EscapeText(&b, []byte(v.Name))
+		b.WriteString("</data>\n")
+		fmt.Fprintf(&b, "      <data key=\"count\">%d</data>\n", v.Count)
+		b.WriteString("    </node>\n")
+	}
+	for _, v := range g.Edges {
+		fmt.Fprintf(&b, "    <edge source=\"n%d\" target=\"n%d\">\n",
+			v.Source, v.Target)
+		fmt.Fprintf(&b, "      <data key=\"weight\">%d</data>\n", v.Weight)
+		b.WriteString("    </edge>\n")
+	}
+	b.WriteString("  </graph>\n</graphml>")
+	return b.String()
+}
+
+// DOT returns the graph as an undirected graph of Graphviz. Nodes are
+// labeled by names, edges by weights.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("graph cooccurrence {\n")
+	for _, v := range g.Nodes {
+		fmt.Fprintf(&b, "  n%d [label=%s, count=%d];\n",
+			v.ID, strconv.Quote(v.Name), v.Count)
+	}
+	for _, v := range g.Edges {
+		fmt.Fprintf(&b, "  n%d -- n%d [weight=%d, label=%d];\n",
+			v.Source, v.Target, v.Weight, v.Weight)
+	}
+	b.WriteString("}")
+	return b.String()
+}
@@ -0,0 +1,293 @@
+// Package graph builds a co-occurrence graph of names found in a text.
+// Nodes of the graph are unique names, and edges connect names that are
+// mentioned together, for example a host and its parasite in one sentence.
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gnames/gnfinder/pkg/ent/segment"
+)
+
+// Window is a part of a text where names co-occur.
+type Window int
+
+// Supported windows.
+const (
+	NoGraph Window = iota
+
+	// Sentence connects names of the same sentence.
+	Sentence
+
+	// Paragraph connects names of the same paragraph.
+	Paragraph
+
+	// Tokens connects names that are close to each other by the number
+	// of words between them.
+	Tokens
+)
+
+var windowStrings = [...]string{"", "sentence", "paragraph", "tokens"}
+
+// String representation of a Window.
+func (w Window) String() string {
+	return windowStrings[w]
+}
+
+// NewWindow takes a string and returns a matching window. If the string
+// is unknown, it returns NoGraph and an error.
+func NewWindow(s string) (Window, error) {
+	s = strings.ToLower(s)
+	if s == "none" {
+		return NoGraph, nil
+	}
+	for i, v := range windowStrings {
+		if s == v {
+			return Window(i), nil
+		}
+	}
+	return NoGraph, fmt.Errorf("unknown co-occurrence window %s", s)
+}
+
+// WindowStrings returns string representations of supported windows.
+func WindowStrings() []string {
+	res := slices.Clone(windowStrings[1:])
+	slices.Sort(res)
+	return res
+}
+
+// Format is a format of the graph export.
+type Format int
+
+// Supported formats.
+const (
+	// JSON graph is a part of JSON output of name-finding.
+	JSON Format = iota
+
+	// GraphML is an XML format of graphs.
+	GraphML
+
+	// DOT is a format of Graphviz.
+	DOT
+)
+
+var formatStrings = [...]string{"json", "graphml", "dot"}
+
+// String representation of a Format.
+func (f Format) String() string {
+	return formatStrings[f]
+}
+
+// NewFormat takes a string and returns a matching format. Empty string
+// returns JSON. If the string is unknown, it returns JSON and an error.
+func NewFormat(s string) (Format, error) {
+	s = strings.ToLower(s)
+	switch s {
+	case "":
+		return JSON, nil
+	case "gv":
+		return DOT, nil
+	}
+	for i, v := range formatStrings {
+		if s == v {
+			return Format(i), nil
+		}
+	}
+	return JSON, fmt.Errorf("unknown graph format %s", s)
+}
+
+// FormatStrings returns string representations of supported formats.
+func FormatStrings() []string {
+	res := slices.Clone(formatStrings[:])
+	slices.Sort(res)
+	return res
+}
+
+// Graph is a co-occurrence graph of names.
+type Graph struct {
+	// Window is the part of a text where names co-occur: "sentence",
+	// "paragraph" or "tokens".
+	Window string `json:"window"`
+
+	// WindowTokens is the maximal distance in words between co-occurring
+	// names of the "tokens" window.
+	WindowTokens int `json:"windowTokens,omitempty"`
+
+	// Nodes are unique names in the order of their first appearance.
+	Nodes []Node `json:"nodes"`
+
+	// Edges connect co-occurring names.
+	Edges []Edge `json:"edges"`
+}
+
+// Node is a unique name.
+type Node struct {
+	// ID of the node is its index in Nodes.
+	ID int `json:"id"`
+
+	// Name is the name of the node.
+	Name string `json:"name"`
+
+	// Count is the number of mentions of the name in the text.
+	Count int `json:"count"`
+}
+
+// Edge connects two co-occurring names.
+type Edge struct {
+	// Source is the ID of the node that appeared first in the text.
+	Source int `json:"source"`
+
+	// Target is the ID of the other node.
+	Target int `json:"target"`
+
+	// Weight is the number of sentences or paragraphs with both names. For
+	// the "tokens" window it is the number of pairs of their mentions
+	// that are close enough.
+	Weight int `json:"weight"`
+}
+
+// Mention is a name found in a text.
+type Mention struct {
+	// Name of the mention, mentions with the same name are the same node.
+	Name string
+
+	// Start of the mention in the text.
+	Start int
+
+	// End of the mention in the text.
+	End int
+}
+
+// Build creates a graph from mentions of names sorted by their position
+// in the text. For the Tokens window names co-occur if there are no more
+// than `tokens` words from the end of one name to the start of the other.
+func Build(text []rune, ms []Mention, w Window, tokens int) *Graph {
+	res := &Graph{Window: w.String(), Nodes: []Node{}, Edges: []Edge{}}
+	if w == Tokens {
+		res.WindowTokens = tokens
+	}
+
+	ids := make(map[string]int)
+	nodes := make([]int, len(ms))
+	for i, m := range ms {
+		id, ok := ids[m.Name]
+		if !ok {
+			id = len(res.Nodes)
+			ids[m.Name] = id
+			res.Nodes = append(res.Nodes, Node{ID: id, Name: m.Name})
+		}
+		res.Nodes[id].Count++
+		nodes[i] = id
+	}
+
+	weights := make(map[[2]int]int)
+	switch w {
+	case Sentence, Paragraph:
+		addWindowEdges(weights, windows(text, ms, w), nodes)
+	case Tokens:
+		addTokenEdges(weights, text, ms, nodes, tokens)
+	}
+	for k, v := range weights {
+		res.Edges = append(res.Edges, Edge{Source: k[0], Target: k[1], Weight: v})
+	}
+	slices.SortFunc(res.Edges, func(a, b Edge) int {
+		if a.Source != b.Source {
+			return a.Source - b.Source
+		}
+		return a.Target - b.Target
+	})
+	return res
+}
+
+// windows returns the index of the sentence or of the paragraph of every
+// mention.
+func windows(text []rune, ms []Mention, w Window) []int {
+	var spans []segment.Span
+	if w == Sentence {
+		spans = segment.Sentences(text)
+	} else {
+		spans = segment.Paragraphs(text)
+	}
+	res := make([]int, len(ms))
+	for i, m := range ms {
+		res[i] = segment.Of(spans, m.Start)
+	}
+	return res
+}
+
+// addWindowEdges connects nodes of every window once per window. Windows
+// of mentions are sorted, mentions outside of windows are skipped.
+func addWindowEdges(weights map[[2]int]int, ws, nodes []int) {
+	for i := 0; i < len(ws); {
+		j := i + 1
+		for j < len(ws) && ws[j] == ws[i] {
+			j++
+		}
+		if ws[i] >= 0 {
+			seen := make(map[[2]int]struct{})
+			for k := i; k < j; k++ {
+				for l := k + 1; l < j; l++ {
+					key, ok := edgeKey(nodes[k], nodes[l])
+					if _, dup := seen[key]; !ok || dup {
+						continue
+					}
+					seen[key] = struct{}{}
+					weights[key]++
+				}
+			}
+		}
+		i = j
+	}
+}
+
+// addTokenEdges connects nodes of mentions that are close to each other.
+func addTokenEdges(
+	weights map[[2]int]int,
+	text []rune,
+	ms []Mention,
+	nodes []int,
+	tokens int,
+) {
+	words := wordIndices(text)
+	for i := range ms {
+		last := words[max(ms[i].Start, ms[i].End-1)]
+		for j := i + 1; j < len(ms); j++ {
+			if words[ms[j].Start]-last > tokens {
+				break
+			}
+			if key, ok := edgeKey(nodes[i], nodes[j]); ok {
+				weights[key]++
+			}
+		}
+	}
+}
+
+// wordIndices returns the number of the word for every position of the
+// text. Spaces get the number of the previous word.
+func wordIndices(text []rune) []int {
+	res := make([]int, len(text)+1)
+	word := -1
+	inWord := false
+	for i, r := range text {
+		isSpace := unicode.IsSpace(r)
+		if !isSpace && !inWord {
+			word++
+		}
+		inWord = !isSpace
+		res[i] = max(word, 0)
+	}
+	res[len(text)] = max(word, 0)
+	return res
+}
+
+// edgeKey returns the key of an edge between two nodes. Nodes do not
+// connect to themselves.
+func edgeKey(n1, n2 int) ([2]int, bool) {
+	if n1 == n2 {
+		return [2]int{}, false
+	}
+	return [2]int{min(n1, n2), max(n1, n2)}, true
+}
@@ -0,0 +1,123 @@
+package graph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWindow(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		s   string
+		res graph.Window
+		err bool
+	}{
+		{"", graph.NoGraph, false},
+		{"none", graph.NoGraph, false},
+		{"Sentence", graph.Sentence, false},
+		{"paragraph", graph.Paragraph, false},
+		{"tokens", graph.Tokens, false},
+		{"page", graph.NoGraph, true},
+	}
+	for _, v := range tests {
+		res, err := graph.NewWindow(v.s)
+		assert.Equal(v.res, res, v.s)
+		assert.Equal(v.err, err != nil, v.s)
+	}
+	assert.Equal([]string{"paragraph", "sentence", "tokens"}, graph.WindowStrings())
+
+	f, err := graph.NewFormat("DOT")
+	assert.Nil(err)
+	assert.Equal(graph.DOT, f)
+	f, err = graph.NewFormat("svg")
+	assert.NotNil(err)
+	assert.Equal(graph.JSON, f)
+}
+
+// mentions finds positions of names in a text.
+func mentions(text string, names ...string) []graph.Mention {
+	var res []graph.Mention
+	runes := []rune(text)
+	var from int
+	for _, v := range names {
+		i := strings.Index(string(runes[from:]), v)
+		start := from + len([]rune(string(runes[from:])[:i]))
+		end := start + len([]rune(v))
+		res = append(res, graph.Mention{Name: v, Start: start, End: end})
+		from = end
+	}
+	return res
+}
+
+func TestBuild(t *testing.T) {
+	assert := assert.New(t)
+	text := "Bubo bubo eats Mus musculus and Mus musculus. " +
+		"Bubo bubo hosts Strigiphilus garylarsoni.\n\n" +
+		"Mus musculus lives with Rattus rattus in one two three four Apis mellifera."
+	ms := mentions(text, "Bubo bubo", "Mus musculus", "Mus musculus",
+		"Bubo bubo", "Strigiphilus garylarsoni", "Mus musculus",
+		"Rattus rattus", "Apis mellifera")
+	runes := []rune(text)
+
+	g := graph.Build(runes, ms, graph.Sentence, 0)
+	assert.Equal("sentence", g.Window)
+	assert.Equal(0, g.WindowTokens)
+	assert.Equal([]graph.Node{
+		{ID: 0, Name: "Bubo bubo", Count: 2},
+		{ID: 1, Name: "Mus musculus", Count: 3},
+		{ID: 2, Name: "Strigiphilus garylarsoni", Count: 1},
+		{ID: 3, Name: "Rattus rattus", Count: 1},
+		{ID: 4, Name: "Apis mellifera", Count: 1},
+	}, g.Nodes)
+	assert.Equal([]graph.Edge{
+		{Source: 0, Target: 1, Weight: 1},
+		{Source: 0, Target: 2, Weight: 1},
+		{Source: 1, Target: 3, Weight: 1},
+		{Source: 1, Target: 4, Weight: 1},
+		{Source: 3, Target: 4, Weight: 1},
+	}, g.Edges)
+
+	g = graph.Build(runes, ms, graph.Paragraph, 0)
+	assert.Equal(6, len(g.Edges))
+	assert.Equal(graph.Edge{Source: 0, Target: 1, Weight: 1}, g.Edges[0])
+
+	g = graph.Build(runes, ms, graph.Tokens, 2)
+	assert.Equal(2, g.WindowTokens)
+	assert.Equal([]graph.Edge{
+		{Source: 0, Target: 1, Weight: 2},
+		{Source: 0, Target: 2, Weight: 1},
+		{Source: 1, Target: 2, Weight: 1},
+	}, g.Edges)
+
+	g = graph.Build(runes, nil, graph.Sentence, 0)
+	assert.Equal(0, len(g.Nodes))
+	assert.Equal(0, len(g.Edges))
+}
+
+func TestExport(t *testing.T) {
+	assert := assert.New(t)
+	text := `Bubo bubo eats "Mus" musculus & Mus musculus.`
+	ms := []graph.Mention{
+		{Name: "Bubo bubo", Start: 0, End: 9},
+		{Name: `Mus "x" & y`, Start: 15, End: 29},
+	}
+	g := graph.Build([]rune(text), ms, graph.Sentence, 0)
+
+	dot := g.Export(graph.DOT)
+	assert.Equal(`graph cooccurrence {
+  n0 [label="Bubo bubo", count=1];
+  n1 [label="Mus \"x\" & y", count=1];
+  n0 -- n1 [weight=1, label=1];
+}`, dot)
+
+	gml := g.Export(graph.GraphML)
+	assert.True(strings.HasPrefix(gml, "<?xml"))
+	assert.Contains(gml, `<data key="name">Mus &#34;x&#34; &amp; y</data>`)
+	assert.Contains(gml, `<edge source="n0" target="n1">`)
+	assert.Contains(gml, `<data key="weight">1</data>`)
+
+	assert.Equal("", g.Export(graph.JSON))
+}
@@ -10,8 +10,9 @@ import (
 // TagTokens is important for both heuristic and Bayes approaches. It analyses
 // tokens and sets up token's indices. Indices determine if a token is a
 // potential unimonial, binomial or trinomial. Then if fills out signfificant
-// number of features pertained to the token.
-func TagTokens(ts []token.TokenSN, d *dict.Dictionary) {
+// number of features pertained to the token. If fuzzy is true, misspelled
+// genera, uninomials and epithets are matched to the dictionaries.
+func TagTokens(ts []token.TokenSN, d *dict.Dictionary, fuzzy bool) {
 	l := len(ts)
 
 	for i := range ts {
@@ -20,8 +21,23 @@ func TagTokens(ts []token.TokenSN, d *dict.Dictionary) {
 			continue
 		}
 		nameTs := ts[i:token.UpperIndex(i, l)]
-		token.SetIndices(nameTs, d)
+		token.SetIndices(nameTs, d, fuzzy)
+		setNothogenus(ts, i)
+		setRankSuffix(ts, i)
 		exploreNameCandidate(nameTs, d)
+		checkAsItalic(ts, i)
+	}
+}
+
+// setNothogenus marks a name candidate as a nothogenus if it is preceded by
+// the hybrid sign, for example "× Triticosecale" or "×Triticosecale".
+func setNothogenus(ts []token.TokenSN, i int) {
+	f := ts[i].Features()
+	if f.Abbr {
+		return
+	}
+	if f.HybridPrefix || (i > 0 && ts[i-1].Features().HybridSign) {
+		f.Hybrid = token.Nothogenus
 	}
 }
 
@@ -54,6 +70,10 @@ func exploreNameCandidate(ts []token.TokenSN, d *dict.Dictionary) bool {
 		return false
 	}
 
+	if checkAsSuprageneric(u) {
+		return true
+	}
+
 	if ok := checkAsGenusSpecies(ts, d); !ok {
 		return false
 	}
@@ -78,6 +98,13 @@ func checkAsSpecies(t token.TokenSN) bool {
 func checkAsGenusSpecies(ts []token.TokenSN, d *dict.Dictionary) bool {
 	g := ts[0]
 	s := ts[g.Indices().Species]
+	if g.Features().UninomialDict == dict.InGenus &&
+		s.Features().SpeciesDict == dict.FuzzyInSpecies &&
+		!s.Features().IsCapitalized {
+		g.SetDecision(token.PossibleBinomial)
+		return true
+	}
+
 	if !checkAsSpecies(s) {
 		if g.Features().UninomialDict == dict.InGenus {
 			g.SetDecision(token.Uninomial)
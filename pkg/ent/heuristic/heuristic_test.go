@@ -17,7 +17,7 @@ func TestHeuristic(t *testing.T) {
          randomly... Pardosa is a very nice when it is not sad. Drosophila
          (Sophophora) melanogaster disagrees!`)
 	ts := token.Tokenize(txt)
-	heuristic.TagTokens(ts, dictionary)
+	heuristic.TagTokens(ts, dictionary, false)
 	tests := map[int]struct {
 		name     string
 		decision token.Decision
@@ -40,3 +40,28 @@ func TestHeuristic(t *testing.T) {
 		}
 	}
 }
+
+func TestRankSuffix(t *testing.T) {
+	assert := assert.New(t)
+	dictionary, _ := dict.LoadDictionary()
+	txt := []rune("Pardosidae and Pardosini but not Perales, Zanini or " +
+		"Giovannini (2001). The order Fagales is.")
+	ts := token.Tokenize(txt)
+	heuristic.TagTokens(ts, dictionary, false)
+	tests := []struct {
+		i        int
+		rank     string
+		decision token.Decision
+	}{
+		{0, "family", token.Uninomial},
+		{2, "tribe", token.Uninomial},
+		{5, "", token.NotName},
+		{6, "", token.NotName},
+		{8, "", token.NotName},
+		{12, "order", token.Uninomial},
+	}
+	for _, v := range tests {
+		assert.Equal(v.rank, ts[v.i].Features().RankSuffix, v.i)
+		assert.Equal(v.decision, ts[v.i].Decision(), v.i)
+	}
+}
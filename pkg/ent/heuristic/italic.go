@@ -0,0 +1,66 @@
+package heuristic
+
+import (
+	"github.com/gnames/gnfinder/pkg/ent/token"
+	"github.com/gnames/gnfinder/pkg/io/dict"
+)
+
+// checkAsItalic uses italics of a marked-up text to find names that are
+// not in the dictionaries, for example new species. The italicized
+// fragment has to contain only the words of the name candidate.
+func checkAsItalic(ts []token.TokenSN, i int) {
+	u := ts[i]
+	f := u.Features()
+	if !f.IsItalic || (i > 0 && ts[i-1].Features().IsItalic) ||
+		u.Decision().Cardinality() > 1 || !isItalicUninomial(u) {
+		return
+	}
+
+	nameTs := ts[i:token.UpperIndex(i, len(ts))]
+	last := 0
+	if sp := u.Indices().Species; sp > 0 && isItalicEpithet(nameTs[sp]) {
+		last = sp
+		if isp := u.Indices().Infraspecies; isp > 0 &&
+			isItalicEpithet(nameTs[isp]) {
+			last = isp
+		}
+	}
+	if next := i + last + 1; next < len(ts) && ts[next].Features().IsItalic {
+		return
+	}
+
+	switch last {
+	case 0:
+		if u.Decision() == token.NotName && !f.Abbr && len(u.Cleaned()) > 3 {
+			u.SetDecision(token.Uninomial)
+		}
+	case u.Indices().Species:
+		u.SetDecision(token.Binomial)
+	default:
+		u.SetDecision(token.Trinomial)
+	}
+}
+
+func isItalicUninomial(u token.TokenSN) bool {
+	f := u.Features()
+	if !f.PotentialBinomialGenus {
+		return false
+	}
+	switch f.UninomialDict {
+	case dict.NotInUninomial, dict.CommonWords:
+		return false
+	}
+	return true
+}
+
+func isItalicEpithet(t token.TokenSN) bool {
+	f := t.Features()
+	if !f.IsItalic || f.IsCapitalized || !f.EndsWithLetter {
+		return false
+	}
+	switch f.SpeciesDict {
+	case dict.NotInSpecies, dict.CommonWords:
+		return false
+	}
+	return true
+}
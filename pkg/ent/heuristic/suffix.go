@@ -0,0 +1,138 @@
+package heuristic
+
+import (
+	"strings"
+
+	"github.com/gnames/gnfinder/pkg/ent/token"
+	"github.com/gnames/gnfinder/pkg/io/dict"
+)
+
+// rankSuffix connects a standardized ending of suprageneric names to
+// a rank.
+type rankSuffix struct {
+	suffix string
+	rank   string
+	// surnameLike is true for endings that are also common endings of
+	// surnames, like "-ales" of "Perales" or "-ini" of "Zanini".
+	surnameLike bool
+}
+
+// rankSuffixes are sorted by length, so the longest ending matches first.
+// Insect orders do not have a standardized ending, but most of them end
+// with "-ptera".
+var rankSuffixes = []rankSuffix{
+	{"mycetidae", "subclass", false},
+	{"mycetes", "class", false},
+	{"phyceae", "class", false},
+	{"oideae", "subfamily", false},
+	{"opsida", "class", false},
+	{"mycota", "phylum", false},
+	{"aceae", "family", false},
+	{"oidea", "superfamily", false},
+	{"phyta", "phylum", false},
+	{"ptera", "order", false},
+	{"idae", "family", false},
+	{"inae", "subfamily", false},
+	{"ales", "order", true},
+	{"ini", "tribe", true},
+}
+
+// minStem is the minimal number of letters before the suffix.
+const minStem = 3
+
+// minSurnameStem is the minimal number of letters before a surname-like
+// suffix. Names of orders and tribes are built from names of genera, and
+// their stems are usually longer than stems of surnames like "Perales",
+// "Zanini" or "Bellini".
+const minSurnameStem = 5
+
+// rankWords precede names of orders and tribes, for example "order Poales"
+// or "tribe Attini".
+var rankWords = map[string]struct{}{
+	"order":   {},
+	"ordo":    {},
+	"ordnung": {},
+	"tribe":   {},
+	"tribus":  {},
+}
+
+// surnameWords precede surnames of collectors and authors.
+var surnameWords = map[string]struct{}{
+	"by":   {},
+	"coll": {},
+	"det":  {},
+	"leg":  {},
+}
+
+// setRankSuffix infers a rank of a capitalized token from its ending.
+func setRankSuffix(ts []token.TokenSN, i int) {
+	t := ts[i]
+	f := t.Features()
+	if f.Abbr || f.HasDash || !f.PotentialBinomialGenus {
+		return
+	}
+	w := t.Cleaned()
+	if strings.ContainsRune(w, '�') {
+		return
+	}
+	for _, v := range rankSuffixes {
+		if len(w) < len(v.suffix)+minStem || !strings.HasSuffix(w, v.suffix) {
+			continue
+		}
+		if v.surnameLike && !afterRankWord(ts, i) &&
+			(len(w) < len(v.suffix)+minSurnameStem || isSurname(ts, i)) {
+			return
+		}
+		f.RankSuffix = v.rank
+		return
+	}
+}
+
+// afterRankWord checks if a token follows a word like "order" or "tribe".
+func afterRankWord(ts []token.TokenSN, i int) bool {
+	if i == 0 {
+		return false
+	}
+	_, ok := rankWords[strings.ToLower(ts[i-1].Cleaned())]
+	return ok
+}
+
+// isSurname checks if a context of a token is typical for surnames: it
+// follows words like "by" or "leg.", or it is followed by a year, "et al."
+// or "&".
+func isSurname(ts []token.TokenSN, i int) bool {
+	if i > 0 {
+		if _, ok := surnameWords[strings.ToLower(ts[i-1].Cleaned())]; ok {
+			return true
+		}
+	}
+	if i+1 == len(ts) {
+		return false
+	}
+	w := strings.Trim(string(ts[i+1].Raw()), "()[],.;:")
+	return w == "&" || w == "et" || isYear(w)
+}
+
+func isYear(w string) bool {
+	if len(w) != 4 {
+		return false
+	}
+	for _, r := range w {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAsSuprageneric detects uninomials that are not in the dictionaries,
+// but have a standardized ending of a suprageneric name, for example
+// "Lycosidae" or "Rosales".
+func checkAsSuprageneric(u token.TokenSN) bool {
+	f := u.Features()
+	if f.UninomialDict != dict.NotInDictionary || f.RankSuffix == "" {
+		return false
+	}
+	u.SetDecision(token.Uninomial)
+	return true
+}
@@ -0,0 +1,127 @@
+package markup
+
+import "unicode"
+
+// mdMark is an emphasis delimiter of Markdown, for example "*" in
+// "*Aus bus*", or "**" in "**Aus bus**".
+type mdMark struct {
+	pos    int
+	n      int
+	char   rune
+	open   bool
+	italic bool
+}
+
+// parseMarkdown removes emphasis delimiters from a Markdown text.
+// Delimiters "*" and "_" define italicized fragments, "***" and "___"
+// define bold italic ones, and "**" and "__" define bold fragments.
+// Delimiters without a pair are kept in the text.
+func parseMarkdown(text []rune) Text {
+	marks := mdMarks(text)
+	b := newBuilder(len(text))
+	for i := 0; i < len(text); i++ {
+		if m, ok := marks[i]; ok {
+			if m.italic && m.open {
+				b.openItalic()
+			} else if m.italic {
+				b.closeItalic()
+			}
+			i += m.n - 1
+			continue
+		}
+		b.add(text[i], i, i+1)
+	}
+	return b.text()
+}
+
+// mdMarks finds pairs of emphasis delimiters. Delimiters do not pair
+// across paragraphs, and inside of code spans.
+func mdMarks(text []rune) map[int]mdMark {
+	res := make(map[int]mdMark)
+	var stack []mdMark
+	for i := 0; i < len(text); i++ {
+		r := text[i]
+		switch {
+		case r == '`':
+			i = codeSpanEnd(text, i)
+		case r == '\n' && isParagraphEnd(text, i):
+			stack = stack[:0]
+		case r == '*' || r == '_':
+			n := 1
+			for i+n < len(text) && text[i+n] == r {
+				n++
+			}
+			m := mdMark{pos: i, n: n, char: r, italic: n%2 == 1}
+			l := len(stack)
+			if l > 0 && stack[l-1].char == r && stack[l-1].n == n &&
+				canClose(text, i, n) {
+				o := stack[l-1]
+				stack = stack[:l-1]
+				o.open = true
+				res[o.pos] = o
+				res[i] = m
+			} else if canOpen(text, i, n) && n <= 3 {
+				stack = append(stack, m)
+			}
+			i += n - 1
+		}
+	}
+	return res
+}
+
+// canOpen checks if a delimiter run is followed by a word. Underscores do
+// not emphasize parts of words, like in "snake_case".
+func canOpen(text []rune, i, n int) bool {
+	if i+n >= len(text) || unicode.IsSpace(text[i+n]) {
+		return false
+	}
+	if text[i] == '_' && i > 0 && isWordRune(text[i-1]) {
+		return false
+	}
+	return true
+}
+
+// canClose checks if a delimiter run follows a word.
+func canClose(text []rune, i, n int) bool {
+	if i == 0 || unicode.IsSpace(text[i-1]) {
+		return false
+	}
+	if text[i] == '_' && i+n < len(text) && isWordRune(text[i+n]) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isParagraphEnd checks if a new line at i is followed by an empty line.
+func isParagraphEnd(text []rune, i int) bool {
+	for j := i + 1; j < len(text); j++ {
+		switch text[j] {
+		case '\n':
+			return true
+		case ' ', '\t', '\r':
+			continue
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// codeSpanEnd returns the position of the backtick that closes a code
+// span started at i. If there is no such backtick in the same paragraph,
+// it returns i.
+func codeSpanEnd(text []rune, i int) int {
+	for j := i + 1; j < len(text); j++ {
+		if text[j] == '`' {
+			return j
+		}
+		if text[j] == '\n' && isParagraphEnd(text, j) {
+			break
+		}
+	}
+	return i
+}
@@ -0,0 +1,166 @@
+// Package markup converts texts with HTML, Markdown or JATS XML markup to
+// plain texts. It keeps positions of italicized fragments, because
+// publishers italicize scientific names, and a map from positions in the
+// plain text back to the original text.
+package markup
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Markup is a markup language of a text.
+type Markup int
+
+// Supported markup languages.
+const (
+	NoMarkup Markup = iota
+	HTML
+	Markdown
+	JATS
+)
+
+var markupStrings = [...]string{"", "html", "markdown", "jats"}
+
+// String representation of a Markup.
+func (m Markup) String() string {
+	return markupStrings[m]
+}
+
+// New takes a string and returns a matching markup. Strings "md" and "xml"
+// are accepted as aliases of "markdown" and "jats". If the string is
+// unknown, it returns NoMarkup and an error.
+func New(s string) (Markup, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return NoMarkup, nil
+	case "html", "htm":
+		return HTML, nil
+	case "markdown", "md":
+		return Markdown, nil
+	case "jats", "xml":
+		return JATS, nil
+	}
+	return NoMarkup, fmt.Errorf("unknown markup %s", s)
+}
+
+// MarkupStrings returns string representations of supported markups.
+func MarkupStrings() []string {
+	res := slices.Clone(markupStrings[1:])
+	slices.Sort(res)
+	return res
+}
+
+// Text is a plain text received from a marked-up text.
+type Text struct {
+	// Text is the text without markup.
+	Text []rune
+
+	// Starts map positions of runes in Text to positions of runes in the
+	// original text.
+	Starts []int
+
+	// Ends map positions of runes in Text to positions in the original
+	// text right after the runes. They differ from Starts for runes that
+	// come from character entities like "&amp;".
+	Ends []int
+
+	// Italics are start and end positions of italicized fragments in Text.
+	Italics [][2]int
+}
+
+// OriginStart returns a position in the original text that corresponds to
+// the start position i in the plain text.
+func (t Text) OriginStart(i int) int {
+	if i < 0 || i >= len(t.Starts) {
+		return t.originLen(i)
+	}
+	return t.Starts[i]
+}
+
+// OriginEnd returns a position in the original text that corresponds to
+// the end position i in the plain text.
+func (t Text) OriginEnd(i int) int {
+	if i <= 0 || i > len(t.Ends) {
+		return t.originLen(i)
+	}
+	return t.Ends[i-1]
+}
+
+// originLen maps positions outside of the plain text.
+func (t Text) originLen(i int) int {
+	if i <= 0 || len(t.Ends) == 0 {
+		return 0
+	}
+	return t.Ends[len(t.Ends)-1]
+}
+
+// Parse removes markup from a text. For markup languages other than
+// Markdown it also substitutes character entities. If markup is NoMarkup,
+// the text is returned as is.
+func Parse(text []rune, m Markup) Text {
+	switch m {
+	case HTML:
+		return parseTags(text, htmlItalics)
+	case JATS:
+		return parseTags(text, jatsItalics)
+	case Markdown:
+		return parseMarkdown(text)
+	default:
+		b := newBuilder(len(text))
+		for i, r := range text {
+			b.add(r, i, i+1)
+		}
+		return b.text()
+	}
+}
+
+// builder assembles a plain text together with its offsets and italics.
+type builder struct {
+	Text
+	italicStart int
+	italicDepth int
+}
+
+func newBuilder(l int) *builder {
+	return &builder{Text: Text{
+		Text:   make([]rune, 0, l),
+		Starts: make([]int, 0, l),
+		Ends:   make([]int, 0, l),
+	}}
+}
+
+// add appends a rune of the plain text that originates from the original
+// text between start and end.
+func (b *builder) add(r rune, start, end int) {
+	b.Text.Text = append(b.Text.Text, r)
+	b.Starts = append(b.Starts, start)
+	b.Ends = append(b.Ends, end)
+}
+
+func (b *builder) openItalic() {
+	if b.italicDepth == 0 {
+		b.italicStart = len(b.Text.Text)
+	}
+	b.italicDepth++
+}
+
+func (b *builder) closeItalic() {
+	if b.italicDepth == 0 {
+		return
+	}
+	b.italicDepth--
+	if b.italicDepth == 0 && len(b.Text.Text) > b.italicStart {
+		b.Italics = append(b.Italics, [2]int{b.italicStart, len(b.Text.Text)})
+	}
+}
+
+// text finishes the plain text and closes unclosed italics.
+func (b *builder) text() Text {
+	if b.italicDepth > 0 {
+		b.italicDepth = 1
+		b.closeItalic()
+	}
+	return b.Text
+}
@@ -0,0 +1,87 @@
+package markup_test
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/markup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		s string
+		m markup.Markup
+	}{
+		{"", markup.NoMarkup},
+		{"html", markup.HTML},
+		{"md", markup.Markdown},
+		{"Markdown", markup.Markdown},
+		{"xml", markup.JATS},
+		{"jats", markup.JATS},
+	}
+	for _, v := range tests {
+		m, err := markup.New(v.s)
+		assert.Nil(err, v.s)
+		assert.Equal(v.m, m, v.s)
+	}
+	m, err := markup.New("rtf")
+	assert.NotNil(err)
+	assert.Equal(markup.NoMarkup, m)
+}
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt string
+		m        markup.Markup
+		text     string
+		italics  []string
+	}{
+		{"html", "<p>The <i>Aus bus</i> and <em>Cus</em></p>", markup.HTML,
+			"\nThe Aus bus and Cus\n", []string{"Aus bus", "Cus"}},
+		{"nested", "<i><b>Aus</b> bus</i>", markup.HTML,
+			"Aus bus", []string{"Aus bus"}},
+		{"entities", "<i>Aus&nbsp;bus</i> &amp; x < 5", markup.HTML,
+			"Aus bus & x < 5", []string{"Aus bus"}},
+		{"script", "<script>var x = '<i>';</script><i>Aus</i>", markup.HTML,
+			"Aus", []string{"Aus"}},
+		{"comment", "Aus<!-- <i>x</i> --> bus", markup.HTML,
+			"Aus bus", nil},
+		{"jats", "<sec><title>On <italic>Aus bus</italic></title></sec>",
+			markup.JATS, "\n\nOn Aus bus\n\n", []string{"Aus bus"}},
+		{"jats no i", "<i>Aus</i> bus", markup.JATS, "Aus bus", nil},
+		{"md star", "The *Aus bus* and **Cus**", markup.Markdown,
+			"The Aus bus and Cus", []string{"Aus bus"}},
+		{"md underscore", "The _Aus bus_ and snake_case_name", markup.Markdown,
+			"The Aus bus and snake_case_name", []string{"Aus bus"}},
+		{"md bold italic", "***Aus bus***", markup.Markdown,
+			"Aus bus", []string{"Aus bus"}},
+		{"md list", "* Aus bus\n* Cus dus", markup.Markdown,
+			"* Aus bus\n* Cus dus", nil},
+		{"md paragraph", "*Aus\n\nbus*", markup.Markdown,
+			"*Aus\n\nbus*", nil},
+		{"md code", "`*Aus*` *bus*", markup.Markdown,
+			"`*Aus*` bus", []string{"bus"}},
+	}
+	for _, v := range tests {
+		res := markup.Parse([]rune(v.txt), v.m)
+		assert.Equal(v.text, string(res.Text), v.msg)
+		var italics []string
+		for _, it := range res.Italics {
+			italics = append(italics, string(res.Text[it[0]:it[1]]))
+		}
+		assert.Equal(v.italics, italics, v.msg)
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	assert := assert.New(t)
+	txt := []rune("<p><i>Aus</i> &amp; <em>bus</em></p>")
+	res := markup.Parse(txt, markup.HTML)
+	assert.Equal("\nAus & bus\n", string(res.Text))
+	assert.Equal("Aus", string(txt[res.OriginStart(1):res.OriginEnd(4)]))
+	assert.Equal("&amp;", string(txt[res.OriginStart(5):res.OriginEnd(6)]))
+	assert.Equal("bus", string(txt[res.OriginStart(7):res.OriginEnd(10)]))
+	assert.Equal(len(txt), res.OriginEnd(len(res.Text)))
+}
@@ -0,0 +1,207 @@
+package markup
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// htmlItalics are HTML tags that italicize text.
+var htmlItalics = map[string]struct{}{"i": {}, "em": {}}
+
+// jatsItalics are JATS tags that italicize text.
+var jatsItalics = map[string]struct{}{"italic": {}}
+
+// blockTags separate words, so they are substituted by a new line.
+var blockTags = map[string]struct{}{
+	// HTML
+	"address": {}, "article": {}, "aside": {}, "blockquote": {}, "br": {},
+	"caption": {}, "dd": {}, "div": {}, "dl": {}, "dt": {}, "figcaption": {},
+	"footer": {}, "h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"header": {}, "hr": {}, "li": {}, "ol": {}, "p": {}, "pre": {},
+	"section": {}, "table": {}, "td": {}, "th": {}, "title": {}, "tr": {},
+	"ul": {},
+	// JATS
+	"abstract": {}, "article-title": {}, "back": {}, "body": {},
+	"element-citation": {}, "fig": {}, "front": {}, "label": {},
+	"list-item": {}, "mixed-citation": {}, "ref": {}, "sec": {},
+	"table-wrap": {}, "kwd": {}, "contrib": {}, "aff": {},
+}
+
+// skipTags have content that is not a part of the text.
+var skipTags = map[string]struct{}{"script": {}, "style": {}}
+
+const (
+	commentStart = "<!--"
+	commentEnd   = "-->"
+	cdataStart   = "<![CDATA["
+	cdataEnd     = "]]>"
+)
+
+// parseTags removes tags from HTML or XML text. Italic tags define
+// italicized fragments, and block tags are substituted by new lines.
+func parseTags(text []rune, italics map[string]struct{}) Text {
+	b := newBuilder(len(text))
+	var skip string
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '<':
+			if hasPrefix(text, i, commentStart) {
+				i = skipTo(text, i+len(commentStart), commentEnd)
+				continue
+			}
+			if hasPrefix(text, i, cdataStart) {
+				end := skipTo(text, i+len(cdataStart), cdataEnd)
+				if skip == "" {
+					for j := i + len(cdataStart); j <= end-len(cdataEnd); j++ {
+						b.add(text[j], j, j+1)
+					}
+				}
+				i = end
+				continue
+			}
+			end, ok := tagEnd(text, i)
+			if !ok {
+				if skip == "" {
+					b.add(text[i], i, i+1)
+				}
+				continue
+			}
+			start := i
+			i = end
+			name, closing, selfClosing := tagName(text[start+1 : end])
+			if skip != "" {
+				if closing && name == skip {
+					skip = ""
+				}
+				continue
+			}
+			if _, ok := skipTags[name]; ok && !closing && !selfClosing {
+				skip = name
+				continue
+			}
+			if _, ok := italics[name]; ok {
+				if closing {
+					b.closeItalic()
+				} else if !selfClosing {
+					b.openItalic()
+				}
+				continue
+			}
+			if _, ok := blockTags[name]; ok {
+				b.add('\n', start, end+1)
+			}
+		case '&':
+			if skip != "" {
+				continue
+			}
+			if s, end, ok := entity(text, i); ok {
+				for _, r := range s {
+					b.add(r, i, end+1)
+				}
+				i = end
+				continue
+			}
+			b.add(text[i], i, i+1)
+		default:
+			if skip == "" {
+				b.add(text[i], i, i+1)
+			}
+		}
+	}
+	return b.text()
+}
+
+func hasPrefix(text []rune, i int, prefix string) bool {
+	for _, r := range prefix {
+		if i >= len(text) || text[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// skipTo returns the position of the last rune of the first occurrence of
+// the suffix after i, or the end of the text.
+func skipTo(text []rune, i int, suffix string) int {
+	for ; i < len(text); i++ {
+		if hasPrefix(text, i, suffix) {
+			return i + len(suffix) - 1
+		}
+	}
+	return len(text) - 1
+}
+
+// tagEnd returns the position of '>' that closes a tag that starts at i.
+// It returns false if '<' does not start a tag, for example in "x < 5".
+func tagEnd(text []rune, i int) (int, bool) {
+	if i+1 >= len(text) {
+		return 0, false
+	}
+	if r := text[i+1]; !unicode.IsLetter(r) && r != '/' && r != '!' && r != '?' {
+		return 0, false
+	}
+	var quote rune
+	for j := i + 1; j < len(text); j++ {
+		r := text[j]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '<':
+			return 0, false
+		case r == '>':
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// tagName returns the low-case name of a tag without namespace prefix,
+// and tells if the tag is a closing or a self-closing one.
+func tagName(tag []rune) (string, bool, bool) {
+	var closing, selfClosing bool
+	if len(tag) > 0 && tag[0] == '/' {
+		closing = true
+		tag = tag[1:]
+	}
+	if len(tag) > 0 && tag[len(tag)-1] == '/' {
+		selfClosing = true
+		tag = tag[:len(tag)-1]
+	}
+	end := len(tag)
+	for i, r := range tag {
+		if unicode.IsSpace(r) || r == '/' {
+			end = i
+			break
+		}
+	}
+	name := strings.ToLower(string(tag[:end]))
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name, closing, selfClosing
+}
+
+// entity substitutes a character entity that starts at i, for example
+// "&amp;" or "&#215;". It returns the substitution and the position of the
+// entity's ';'.
+func entity(text []rune, i int) (string, int, bool) {
+	limit := 32
+	for j := i + 1; j < len(text) && j < i+limit; j++ {
+		r := text[j]
+		if r == ';' {
+			ent := string(text[i : j+1])
+			s := html.UnescapeString(ent)
+			return s, j, s != ent
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#' {
+			break
+		}
+	}
+	return "", 0, false
+}
@@ -15,11 +15,36 @@ import (
 	"github.com/gnames/gnfinder/pkg/io/nlpfs"
 )
 
+// TagTokens decides which tokens are parts of names using Bayes'
+// posterior odds. Odds of italicized words are multiplied by italicsOdds.
 func TagTokens(
 	ts []token.TokenSN,
 	d *dict.Dictionary,
 	nb bayes.Bayes,
-	thr float64,
+	thr, italicsOdds float64,
+) {
+	tagTokens(ts, d, nb, thr, italicsOdds, nameFrequency(), evenOdds())
+}
+
+// TagChecklistTokens works like TagTokens for a line of a checklist or
+// a cell of a table. Most of capitalized words in checklists start names,
+// so prior odds of names are much higher than in running texts.
+func TagChecklistTokens(
+	ts []token.TokenSN,
+	d *dict.Dictionary,
+	nb bayes.Bayes,
+	thr, italicsOdds float64,
+) {
+	tagTokens(ts, d, nb, thr, italicsOdds,
+		checklistFrequency(), checklistEpithetFrequency())
+}
+
+func tagTokens(
+	ts []token.TokenSN,
+	d *dict.Dictionary,
+	nb bayes.Bayes,
+	thr, italicsOdds float64,
+	priorOdds, epithetOdds map[feature.Class]int,
 ) {
 	for i := range ts {
 		t := ts[i]
@@ -31,12 +56,18 @@ func TagTokens(
 		t.Features().SetUninomialDict(t.Cleaned(), d)
 		ts2 := ts[i:token.UpperIndex(i, len(ts))]
 		fs := NewFeatureSet(ts2)
-		priorOdds := nameFrequency()
-		odds, err := calcOdds(nb, t, &fs, priorOdds)
+		prior := priorOdds
+		if t.Features().InTable {
+			prior = tableFrequency()
+		}
+		odds, err := calcOdds(nb, t, &fs, prior, epithetOdds)
 		if err != nil {
 			slog.Error("Cannot calculate Bayesian odds", "token", ts[i], "error", err)
 			continue
 		}
+		addItalics(odds, ts2, italicsOdds)
+		addFuzzy(odds, ts2)
+		addRankSuffix(odds, t)
 		processBayesResults(odds, ts, i, thr, d)
 	}
 }
@@ -129,10 +160,8 @@ func calcOdds(
 	nb bayes.Bayes,
 	t token.TokenSN,
 	fs *FeatureSet,
-	priorOdds map[feature.Class]int,
+	priorOdds, epithetOdds map[feature.Class]int,
 ) ([]posterior.Odds, error) {
-	evenOdds := map[feature.Class]int{IsName: 1, IsNotName: 1}
-
 	oddsUni, err := nb.PosteriorOdds(
 		features(fs.Uninomial),
 		bayes.OptPriorOdds(priorOdds),
@@ -146,7 +175,7 @@ func calcOdds(
 	}
 	oddsSp, err := nb.PosteriorOdds(
 		features(fs.Species),
-		bayes.OptPriorOdds(evenOdds),
+		bayes.OptPriorOdds(epithetOdds),
 	)
 	if err != nil {
 		slog.Error("Cannot get posterior odds for species", "error", err)
@@ -157,7 +186,7 @@ func calcOdds(
 		return []posterior.Odds{oddsUni, oddsSp}, nil
 	}
 	f := features(fs.InfraSp)
-	oddsInfraSp, err := nb.PosteriorOdds(f, bayes.OptPriorOdds(evenOdds))
+	oddsInfraSp, err := nb.PosteriorOdds(f, bayes.OptPriorOdds(epithetOdds))
 	if err != nil {
 		slog.Error("Cannot get posterior odds for infraspecies", "error", err)
 	}
@@ -165,6 +194,84 @@ func calcOdds(
 	return []posterior.Odds{oddsUni, oddsSp, oddsInfraSp}, nil
 }
 
+// addItalics raises posterior odds of uninomials, species and
+// infraspecies that are written in italics. Italics are not a part of
+// Bayes' training data, so the odds ratio of italics is a setting.
+func addItalics(odds []posterior.Odds, ts []token.TokenSN, ratio float64) {
+	if ratio <= 1 {
+		return
+	}
+	u := ts[0]
+	if u.Features().IsItalic {
+		fixedOdds(&odds[0], "uniItalic", ratio)
+	}
+	if len(odds) > 1 && ts[u.Indices().Species].Features().IsItalic {
+		fixedOdds(&odds[1], "spItalic", ratio)
+	}
+	if len(odds) > 2 && ts[u.Indices().Infraspecies].Features().IsItalic {
+		fixedOdds(&odds[2], "ispItalic", ratio)
+	}
+}
+
+// rankSuffixOdds is the odds ratio of uninomials that are not in the
+// dictionaries, but have a standardized ending of a suprageneric name, for
+// example "Pardosidae". Bayes' training data do not have such endings, so
+// the ratio is fixed. These endings are rare among other capitalized words,
+// and the ratio outweighs low odds of words that are not in the
+// dictionaries.
+const rankSuffixOdds = 1000.0
+
+// addRankSuffix raises posterior odds of uninomials that are found only by
+// their rank suffix.
+func addRankSuffix(odds []posterior.Odds, u token.TokenSN) {
+	f := u.Features()
+	if f.RankSuffix != "" && f.UninomialDict == dict.NotInDictionary {
+		fixedOdds(&odds[0], "uniRankSuffix", rankSuffixOdds)
+	}
+}
+
+// fuzzyOdds is the odds ratio of words that are one edit away from
+// dictionary words. Bayes takes the likelihood of the exact match for them
+// (see trainedDict), and this fixed ratio lowers it, because a misspelled
+// word is a weaker sign of a name than an exact match. With the current
+// training data a fuzzy match still weighs more than an ambiguous exact
+// match.
+const fuzzyOdds = 0.001
+
+// addFuzzy lowers posterior odds of name parts that were found by fuzzy
+// matching.
+func addFuzzy(odds []posterior.Odds, ts []token.TokenSN) {
+	u := ts[0]
+	switch u.Features().UninomialDict {
+	case dict.FuzzyInGenus, dict.FuzzyInUninomial:
+		fixedOdds(&odds[0], "uniFuzzy", fuzzyOdds)
+	}
+	if len(odds) > 1 &&
+		ts[u.Indices().Species].Features().SpeciesDict == dict.FuzzyInSpecies {
+		fixedOdds(&odds[1], "spFuzzy", fuzzyOdds)
+	}
+	if len(odds) > 2 &&
+		ts[u.Indices().Infraspecies].Features().SpeciesDict == dict.FuzzyInSpecies {
+		fixedOdds(&odds[2], "ispFuzzy", fuzzyOdds)
+	}
+}
+
+// fixedOdds multiplies odds of the IsName class by a ratio that does not
+// come from Bayes' training data. The ratio is saved as a likelihood of
+// a feature with the "fixed" value, so odds details show it apart from
+// trained features.
+func fixedOdds(odds *posterior.Odds, name string, ratio float64) {
+	f := feature.Feature{Name: feature.Name(name), Value: "fixed"}
+	odds.ClassOdds[IsName] *= ratio
+	odds.ClassOdds[IsNotName] /= ratio
+	odds.Likelihoods[IsName][f] = ratio
+	odds.Likelihoods[IsNotName][f] = 1 / ratio
+	odds.MaxClass, odds.MaxOdds = IsName, odds.ClassOdds[IsName]
+	if odds.ClassOdds[IsNotName] > odds.MaxOdds {
+		odds.MaxClass, odds.MaxOdds = IsNotName, odds.ClassOdds[IsNotName]
+	}
+}
+
 func nameFrequency() map[feature.Class]int {
 	return map[feature.Class]int{
 		IsName:    1,
@@ -172,6 +279,37 @@ func nameFrequency() map[feature.Class]int {
 	}
 }
 
+func evenOdds() map[feature.Class]int {
+	return map[feature.Class]int{IsName: 1, IsNotName: 1}
+}
+
+// tableFrequency is the frequency of names among capitalized words of
+// tables found in running texts.
+func tableFrequency() map[feature.Class]int {
+	return map[feature.Class]int{
+		IsName:    1,
+		IsNotName: 1,
+	}
+}
+
+// checklistFrequency is the frequency of names among capitalized words
+// of checklists and tables.
+func checklistFrequency() map[feature.Class]int {
+	return map[feature.Class]int{
+		IsName:    10,
+		IsNotName: 1,
+	}
+}
+
+// checklistEpithetFrequency is the frequency of epithets among words
+// that follow possible genera in checklists.
+func checklistEpithetFrequency() map[feature.Class]int {
+	return map[feature.Class]int{
+		IsName:    4,
+		IsNotName: 1,
+	}
+}
+
 func BayesWeights() (map[lang.Language]bayes.Bayes, error) {
 	var err error
 	bw := make(map[lang.Language]bayes.Bayes)
@@ -26,14 +26,95 @@ cheilum, 1 5s. per doz.
 Conostylis americana, 2i. 6d.
 			`)
 	tokens := token.Tokenize(txt)
-	heuristic.TagTokens(tokens, dictionary)
+	heuristic.TagTokens(tokens, dictionary, false)
 	nb := weights[lang.English]
 
 	tkn := tokens[10]
 	assert.Equal("Cymbidium", tkn.Cleaned())
 	assert.Equal(token.Uninomial, tkn.Decision())
 
-	nlp.TagTokens(tokens, dictionary, nb, 80.0)
+	nlp.TagTokens(tokens, dictionary, nb, 80.0, 10.0)
 	assert.Equal("Cymbidium", tkn.Cleaned())
 	assert.Equal(token.BayesBinomial, tkn.Decision())
 }
+
+func TestTagChecklist(t *testing.T) {
+	assert := assert.New(t)
+	dictionary, err := dict.LoadDictionary()
+	assert.Nil(err)
+	weights, err := nlp.BayesWeights()
+	assert.Nil(err)
+	nb := weights[lang.English]
+
+	txt := []rune("Zyxolia kerbanensis")
+	tokens := token.Tokenize(txt)
+	heuristic.TagTokens(tokens, dictionary, false)
+	nlp.TagTokens(tokens, dictionary, nb, 80.0, 10.0)
+	assert.Equal(token.NotName, tokens[0].Decision())
+
+	tokens = token.Tokenize(txt)
+	heuristic.TagTokens(tokens, dictionary, false)
+	nlp.TagChecklistTokens(tokens, dictionary, nb, 80.0, 10.0)
+	assert.Equal(token.BayesBinomial, tokens[0].Decision())
+
+	tokens = token.Tokenize([]rune("Meeting notes"))
+	heuristic.TagTokens(tokens, dictionary, false)
+	nlp.TagChecklistTokens(tokens, dictionary, nb, 80.0, 10.0)
+	assert.Equal(token.NotName, tokens[0].Decision())
+}
+
+// TestItalics checks that italics raise odds of names by a fixed ratio, but
+// do not make names out of words that are clearly not names.
+func TestItalics(t *testing.T) {
+	assert := assert.New(t)
+	dictionary, err := dict.LoadDictionary()
+	assert.Nil(err)
+	weights, err := nlp.BayesWeights()
+	assert.Nil(err)
+	nb := weights[lang.English]
+
+	for _, v := range []string{"Zeitschrift", "Endeavour", "Washington"} {
+		txt := []rune("The " + v + " is old.")
+		tokens := token.Tokenize(txt)
+		heuristic.TagTokens(tokens, dictionary, false)
+		nlp.TagTokens(tokens, dictionary, nb, 80.0, 10.0)
+		odds := tokens[1].NLP().Odds
+
+		tokens = token.Tokenize(txt)
+		tokens[1].Features().IsItalic = true
+		heuristic.TagTokens(tokens, dictionary, false)
+		nlp.TagTokens(tokens, dictionary, nb, 80.0, 10.0)
+		assert.InDelta(odds*10, tokens[1].NLP().Odds, odds/1000, v)
+		assert.Less(tokens[1].NLP().Odds, 1.0, v)
+		assert.Equal(10.0, tokens[1].NLP().OddsDetails["uniItalic: fixed"], v)
+		assert.NotEqual(token.BayesUninomial, tokens[1].Decision(), v)
+	}
+}
+
+// TestTrainedFeatures checks that features of name candidates are known to
+// the trained weights, because Bayes ignores unknown features.
+func TestTrainedFeatures(t *testing.T) {
+	assert := assert.New(t)
+	dictionary, err := dict.LoadDictionary()
+	assert.Nil(err)
+	weights, err := nlp.BayesWeights()
+	assert.Nil(err)
+	trained := weights[lang.English].Inspect().FeatureCases
+
+	txt := []rune("The Pardosidae and Pardosa moesta are spiders, " +
+		"Parosa moestta and Bubo bubo bubbo are not.")
+	tokens := token.Tokenize(txt)
+	token.SetItalics(tokens, [][2]int{{19, 33}})
+	heuristic.TagTokens(tokens, dictionary, true)
+	for i := range tokens {
+		fs := nlp.NewFeatureSet(tokens[i:token.UpperIndex(i, len(tokens))])
+		for _, f := range fs.Flatten() {
+			assert.Contains(trained, string(f.Name), tokens[i].Cleaned())
+			switch f.Name {
+			case "uniDict", "spDict", "ispDict":
+				assert.Contains(trained[string(f.Name)], string(f.Value),
+					tokens[i].Cleaned())
+			}
+		}
+	}
+}
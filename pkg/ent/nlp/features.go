@@ -60,7 +60,7 @@ func (fs *FeatureSet) convertFeatures(
 ) {
 	var uniDict, spDict, ispDict string
 	if !uni.Features().Abbr {
-		uniDict = uni.Features().UninomialDict.String()
+		uniDict = trainedDict(uni.Features().UninomialDict)
 		fs.Uninomial = append(fs.Uninomial,
 			BayesF{"uniLen", strconv.Itoa(len(uni.Cleaned()))},
 			BayesF{"abbr", "false"},
@@ -72,7 +72,7 @@ func (fs *FeatureSet) convertFeatures(
 		fs.Uninomial = append(fs.Uninomial, BayesF{"uniEnd3", w3})
 	}
 	if uni.Indices().Species > 0 {
-		spDict = sp.Features().SpeciesDict.String()
+		spDict = trainedDict(sp.Features().SpeciesDict)
 		fs.Species = append(fs.Species,
 			BayesF{"spLen", strconv.Itoa(len(sp.Cleaned()))},
 		)
@@ -94,7 +94,7 @@ func (fs *FeatureSet) convertFeatures(
 	}
 
 	if uni.Indices().Infraspecies > 0 {
-		ispDict = isp.Features().SpeciesDict.String()
+		ispDict = trainedDict(isp.Features().SpeciesDict)
 		fs.InfraSp = append(fs.InfraSp,
 			BayesF{"ispLen", strconv.Itoa(len(isp.Cleaned()))},
 		)
@@ -119,6 +119,24 @@ func (fs *FeatureSet) convertFeatures(
 	}
 }
 
+// trainedDict returns a dictionary type known to Bayes' training data.
+// Fuzzy matches are absent from the training data, so a fuzzy match takes
+// the likelihood of the exact match it is one edit away from: fuzzyInGenus
+// becomes inGenus, fuzzyInUninomial becomes inUninomial, and
+// fuzzyInSpecies becomes inSpecies. The uncertainty of the edit is added
+// later as a fixed odds ratio (see addFuzzy).
+func trainedDict(d dict.DictionaryType) string {
+	switch d {
+	case dict.FuzzyInGenus:
+		return dict.InGenus.String()
+	case dict.FuzzyInUninomial:
+		return dict.InUninomial.String()
+	case dict.FuzzyInSpecies:
+		return dict.InSpecies.String()
+	}
+	return d.String()
+}
+
 func wordEnd(t token.TokenSN) string {
 	name := []rune(t.Cleaned())
 	l := len(name)
@@ -0,0 +1,90 @@
+// Package offset describes units of start and end positions of names in
+// a text. Go and Rust count strings in bytes, Python in Unicode code
+// points, JavaScript and Java in UTF-16 code units.
+package offset
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Unit of offsets.
+type Unit int
+
+// Supported units of offsets.
+const (
+	// Rune is a Unicode code point, or a UTF-8 character.
+	Rune Unit = iota
+
+	// Byte is a byte of UTF-8 encoded text.
+	Byte
+
+	// UTF16 is a UTF-16 code unit. Characters outside of the Basic
+	// Multilingual Plane, like emoji, take two units.
+	UTF16
+)
+
+var unitStrings = [...]string{"runes", "bytes", "utf16"}
+
+// String representation of a Unit.
+func (u Unit) String() string {
+	return unitStrings[u]
+}
+
+// New takes a string and returns a matching unit. Empty string and
+// "chars" return Rune, "utf-16" is an alias of "utf16". If the string is
+// unknown, it returns Rune and an error.
+func New(s string) (Unit, error) {
+	switch strings.ToLower(s) {
+	case "", "runes", "rune", "chars":
+		return Rune, nil
+	case "bytes", "byte":
+		return Byte, nil
+	case "utf16", "utf-16":
+		return UTF16, nil
+	}
+	return Rune, fmt.Errorf("unknown offset unit %s", s)
+}
+
+// UnitStrings returns string representations of supported units.
+func UnitStrings() []string {
+	return slices.Clone(unitStrings[:])
+}
+
+// Converter returns a function that converts an offset in runes of the
+// text to the offset in the given unit.
+func Converter(text []rune, u Unit) func(int) int {
+	if u == Rune {
+		return func(i int) int { return i }
+	}
+	res := make([]int, len(text)+1)
+	var n int
+	for i, r := range text {
+		res[i] = n
+		n += runeLen(r, u)
+	}
+	res[len(text)] = n
+	return func(i int) int {
+		if i < 0 || i >= len(res) {
+			return i
+		}
+		return res[i]
+	}
+}
+
+func runeLen(r rune, u Unit) int {
+	var l int
+	if u == UTF16 {
+		l = utf16.RuneLen(r)
+	} else {
+		l = utf8.RuneLen(r)
+	}
+	if l < 0 {
+		// invalid runes are encoded as U+FFFD
+		return runeLen(utf8.RuneError, u)
+	}
+	return l
+}
@@ -0,0 +1,51 @@
+package offset_test
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		s      string
+		unit   offset.Unit
+		hasErr bool
+	}{
+		{"", offset.Rune, false},
+		{"runes", offset.Rune, false},
+		{"chars", offset.Rune, false},
+		{"Bytes", offset.Byte, false},
+		{"utf16", offset.UTF16, false},
+		{"UTF-16", offset.UTF16, false},
+		{"utf32", offset.Rune, true},
+	}
+	for _, v := range tests {
+		u, err := offset.New(v.s)
+		assert.Equal(v.unit, u, v.s)
+		assert.Equal(v.hasErr, err != nil, v.s)
+	}
+	assert.Equal([]string{"runes", "bytes", "utf16"}, offset.UnitStrings())
+}
+
+func TestConverter(t *testing.T) {
+	assert := assert.New(t)
+	// 'я' takes 2 bytes, '😀' and '𝑃' take 4 bytes and 2 UTF-16 units.
+	text := []rune("я😀 𝑃ardosa")
+	tests := []struct {
+		unit offset.Unit
+		res  []int
+	}{
+		{offset.Rune, []int{0, 1, 2, 3, 4, 10}},
+		{offset.Byte, []int{0, 2, 6, 7, 11, 17}},
+		{offset.UTF16, []int{0, 1, 3, 4, 6, 12}},
+	}
+	for _, v := range tests {
+		conv := offset.Converter(text, v.unit)
+		for i, o := range []int{0, 1, 2, 3, 4, 10} {
+			assert.Equal(v.res[i], conv(o), v.unit.String())
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Package order describes sorting of unique names.
+package order
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Order is a way to sort unique names.
+type Order int
+
+// Supported orders.
+const (
+	// Alphabetical sorts names by their normalized form.
+	Alphabetical Order = iota
+
+	// Frequency sorts names by the number of their occurrences, most
+	// frequent names go first. Names with the same count are sorted
+	// alphabetically.
+	Frequency
+
+	// Appearance sorts names by their first occurrence in the text.
+	Appearance
+)
+
+var orderStrings = [...]string{"alphabetical", "frequency", "appearance"}
+
+// String representation of an Order.
+func (o Order) String() string {
+	return orderStrings[o]
+}
+
+// New takes a string and returns a matching order. Empty string returns
+// Alphabetical, "alpha", "freq" and "first" are aliases. If the string is
+// unknown, it returns Alphabetical and an error.
+func New(s string) (Order, error) {
+	switch strings.ToLower(s) {
+	case "", "alphabetical", "alpha":
+		return Alphabetical, nil
+	case "frequency", "freq":
+		return Frequency, nil
+	case "appearance", "first":
+		return Appearance, nil
+	}
+	return Alphabetical, fmt.Errorf("unknown order %s", s)
+}
+
+// OrderStrings returns string representations of supported orders.
+func OrderStrings() []string {
+	res := slices.Clone(orderStrings[:])
+	slices.Sort(res)
+	return res
+}
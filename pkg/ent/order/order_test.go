@@ -0,0 +1,38 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/order"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		s   string
+		res order.Order
+		err bool
+	}{
+		{"", order.Alphabetical, false},
+		{"Alpha", order.Alphabetical, false},
+		{"frequency", order.Frequency, false},
+		{"freq", order.Frequency, false},
+		{"appearance", order.Appearance, false},
+		{"first", order.Appearance, false},
+		{"random", order.Alphabetical, true},
+	}
+	for _, v := range tests {
+		res, err := order.New(v.s)
+		assert.Equal(v.res, res, v.s)
+		assert.Equal(v.err, err != nil, v.s)
+		if !v.err {
+			res2, _ := order.New(res.String())
+			assert.Equal(res, res2, v.s)
+		}
+	}
+	assert.Equal(
+		[]string{"alphabetical", "appearance", "frequency"},
+		order.OrderStrings(),
+	)
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"strings"
+)
+
+// expandAbbreviations goes through found names and tries to resolve
+// abbreviated genera (for example "P. moesta") to the most recent full
+// genus in the document that starts with the same letters (for example
+// "Pardosa lapidicina"). Only genera of bi- and trinomials are used for
+// the expansion.
+func expandAbbreviations(names []Name) {
+	// genera keeps full genera in the order they appeared in the text.
+	var genera []string
+	for i := range names {
+		if names[i].Cardinality < 2 {
+			continue
+		}
+		words := strings.SplitN(trimHybridSign(names[i].Name), " ", 2)
+		if len(words) < 2 {
+			continue
+		}
+		abbr, ok := genusAbbr(words[0])
+		if !ok {
+			genera = append(genera, words[0])
+			continue
+		}
+		genus, ambiguous := matchGenus(abbr, genera)
+		if genus == "" {
+			continue
+		}
+		names[i].ExpandedName = genus + " " + words[1]
+		names[i].ExpandedAmbiguous = ambiguous
+	}
+}
+
+// genusAbbr returns letters of an abbreviated genus without the period.
+func genusAbbr(genus string) (string, bool) {
+	if !strings.HasSuffix(genus, ".") {
+		return "", false
+	}
+	return strings.TrimSuffix(genus, "."), true
+}
+
+// matchGenus finds the most recent genus that starts with the abbreviation.
+// It also reports if there were other genera in the document that match the
+// abbreviation as well.
+func matchGenus(abbr string, genera []string) (string, bool) {
+	var res string
+	matches := make(map[string]struct{})
+	for i := len(genera) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(genera[i], abbr) {
+			continue
+		}
+		if res == "" {
+			res = genera[i]
+		}
+		matches[genera[i]] = struct{}{}
+	}
+	return res, len(matches) > 1
+}
@@ -0,0 +1,321 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/gnames/gnfinder/pkg/ent/token"
+)
+
+// Annotation is a normalized nomenclatural annotation that marks
+// a nomenclatural act, for example a description of a new species.
+type Annotation int
+
+// Possible Annotations
+const (
+	NoAnnot Annotation = iota
+	SpNov
+	SubspNov
+	CombNov
+	NomNov
+	GenNov
+	FamNov
+	StatNov
+	StatRev
+	NomNud
+	SynNov
+)
+
+var annotationsStrings = [...]string{"NO_ANNOT", "SP_NOV", "SUBSP_NOV",
+	"COMB_NOV", "NOM_NOV", "GEN_NOV", "FAM_NOV", "STAT_NOV", "STAT_REV",
+	"NOM_NUD", "SYN_NOV",
+}
+
+// String representation of an Annotation.
+func (a Annotation) String() string {
+	return annotationsStrings[a]
+}
+
+// annotRank is the part of an annotation that tells what kind of
+// nomenclatural act happened.
+type annotRank int
+
+const (
+	spRank annotRank = iota
+	subspRank
+	combRank
+	nomRank
+	genRank
+	famRank
+	statRank
+	synRank
+)
+
+// annotRanks contain Latin abbreviations as well as English and German
+// words.
+var annotRanks = map[string]annotRank{
+	"sp": spRank, "spec": spRank, "species": spRank, "art": spRank,
+	"subsp": subspRank, "ssp": subspRank, "subspecies": subspRank,
+	"unterart": subspRank,
+	"comb":     combRank, "combinatio": combRank, "combination": combRank,
+	"kombination": combRank,
+	"nom":         nomRank, "nomen": nomRank, "name": nomRank,
+	"gen": genRank, "genus": genRank, "gattung": genRank,
+	"fam": famRank, "familia": famRank, "family": famRank, "familie": famRank,
+	"stat": statRank, "status": statRank,
+	"syn": synRank, "synonym": synRank, "synonymy": synRank,
+}
+
+// annotNovelty is the part of an annotation that tells if the act is new,
+// revived or invalid.
+type annotNovelty int
+
+const (
+	// nov can be placed before or after the rank ("sp. n.", "n. sp.").
+	nov annotNovelty = iota
+	// novAfter is placed only after the rank ("genus novum").
+	novAfter
+	// novBefore is placed only before the rank ("new species", "neue Art").
+	novBefore
+	rev
+	nud
+)
+
+var annotNovelties = map[string]annotNovelty{
+	"n": nov, "nv": nov, "nov": nov,
+	"nova": novAfter, "novum": novAfter, "novus": novAfter,
+	"new": novBefore, "neu": novBefore, "neue": novBefore, "neuer": novBefore,
+	"neues": novBefore,
+	"rev":   rev, "revived": rev, "revalidated": rev,
+	"nud": nud, "nudum": nud,
+}
+
+// annotNomen finds a nomenclatural annotation for a name that starts
+// with the token i. First it looks for the annotation right after the
+// name, optionally separated from it by authors and a year. Latin
+// abbreviations with "n." or "nov." are also found a few words after the
+// name. If there is no annotation after the name, it looks for one right
+// before the name that starts a line or a sentence, like in "New species.
+// Pardosa moesta", so phrases like "not a new species: Pardosa moesta" are
+// not annotations. Tokens with indices less than 'from' belong to previous
+// names or their annotations and are not used. The function returns the
+// index of the first token that is not used by the name and its
+// annotation.
+func annotNomen(
+	ts []token.TokenSN,
+	text []rune,
+	i, from int,
+	name *Name,
+) int {
+	limit := 5
+	j := i
+	for j < len(ts) && ts[j].Start() < name.OffsetEnd {
+		j++
+	}
+
+	for k := j; k < len(ts) && k < j+limit; k++ {
+		if end, a := annotAt(ts, k); a != NoAnnot {
+			setAnnot(ts, k, end, a, name)
+			return end
+		}
+		if !isAuthorship(ts[k]) || ts[k].Decision() != token.NotName {
+			break
+		}
+	}
+
+	// open nomenclature qualifiers are not nomenclatural annotations.
+	after := make([]int, 0, limit)
+	for k := j; k < len(ts) && k < j+limit; k++ {
+		if ts[k].Decision() != token.NotName {
+			break
+		}
+		if ts[k].Features().Qualifier == token.NoQualifier {
+			after = append(after, k)
+		}
+	}
+
+	for k := range after {
+		if a := noSpaceAnnot(ts[after[k]]); a != NoAnnot &&
+			isAbbrAnnot(ts[after[k]]) {
+			setAnnot(ts, after[k], after[k]+1, a, name)
+			return after[k] + 1
+		}
+		if k+1 == len(after) || after[k+1] != after[k]+1 {
+			continue
+		}
+		if a := pairAnnot(ts[after[k]], ts[after[k+1]]); a != NoAnnot &&
+			isAbbrAnnot(ts[after[k]], ts[after[k+1]]) {
+			setAnnot(ts, after[k], after[k]+2, a, name)
+			return after[k] + 2
+		}
+	}
+
+	if i-1 >= from && startsSentence(ts, text, i-1) {
+		if a := noSpaceAnnot(ts[i-1]); a != NoAnnot {
+			setAnnot(ts, i-1, i, a, name)
+			return j
+		}
+	}
+	if i-2 >= from && startsSentence(ts, text, i-2) {
+		if a := pairAnnot(ts[i-2], ts[i-1]); a != NoAnnot {
+			setAnnot(ts, i-2, i, a, name)
+			return j
+		}
+	}
+
+	name.AnnotNomenType = NoAnnot.String()
+	return j
+}
+
+// startsSentence checks if the token k starts the text, a line or
+// a sentence.
+func startsSentence(ts []token.TokenSN, text []rune, k int) bool {
+	if k == 0 {
+		return true
+	}
+	prev := ts[k-1]
+	if strings.ContainsAny(string(text[prev.End():ts[k].Start()]), "\n\f") {
+		return true
+	}
+	raw := strings.TrimSpace(string(prev.Raw()))
+	return strings.ContainsAny(raw[len(raw)-1:], ".:;!?")
+}
+
+// annotAt detects an annotation that starts at the token k. It returns
+// the index of the token after the annotation.
+func annotAt(ts []token.TokenSN, k int) (int, Annotation) {
+	if a := noSpaceAnnot(ts[k]); a != NoAnnot {
+		return k + 1, a
+	}
+	if k+1 < len(ts) {
+		if a := pairAnnot(ts[k], ts[k+1]); a != NoAnnot {
+			return k + 2, a
+		}
+	}
+	return k, NoAnnot
+}
+
+// authorParticles can be a part of authors of a name.
+var authorParticles = map[string]struct{}{
+	"&": {}, "et": {}, "ex": {}, "de": {}, "da": {}, "du": {},
+	"van": {}, "von": {}, "der": {}, "den": {}, "le": {}, "la": {},
+}
+
+// isAuthorship checks if a token might be a part of authors or of a year
+// that can be placed between a name and its annotation, like in
+// "Pardosa moesta Banks, 1892, sp. n.".
+func isAuthorship(t token.TokenSN) bool {
+	if t.Features().IsCapitalized {
+		return true
+	}
+	w := strings.Trim(string(t.Raw()), " ()[],.;:")
+	if _, ok := authorParticles[w]; ok {
+		return true
+	}
+	if len(w) != 4 {
+		return false
+	}
+	for _, r := range w {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAbbrAnnot checks if an annotation uses "n.", "nv." or "nov." to
+// mark a nomenclatural novelty.
+func isAbbrAnnot(ts ...token.TokenSN) bool {
+	for _, t := range ts {
+		for _, w := range strings.Split(t.Cleaned(), "�") {
+			if n, ok := annotNovelties[strings.ToLower(w)]; ok && n == nov {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setAnnot saves annotation data created from tokens with indices from
+// start to end (exclusive) to the name.
+func setAnnot(
+	ts []token.TokenSN,
+	start, end int,
+	a Annotation,
+	name *Name,
+) {
+	raws := make([]string, 0, end-start)
+	for _, t := range ts[start:end] {
+		raws = append(raws, strings.TrimSpace(string(t.Raw())))
+	}
+	name.AnnotNomen = strings.Join(raws, " ")
+	name.AnnotNomenType = a.String()
+	name.AnnotNomenStart = ts[start].Start()
+	name.AnnotNomenEnd = ts[end-1].End()
+}
+
+// noSpaceAnnot detects annotations without spaces, like "sp.nov.".
+func noSpaceAnnot(t token.TokenSN) Annotation {
+	words := strings.Split(t.Cleaned(), "�")
+	if len(words) != 2 {
+		return NoAnnot
+	}
+	return wordsAnnot(words[0], words[1])
+}
+
+// pairAnnot detects annotations made of two tokens, like "sp. nov.".
+func pairAnnot(t1, t2 token.TokenSN) Annotation {
+	return wordsAnnot(t1.Cleaned(), t2.Cleaned())
+}
+
+func wordsAnnot(w1, w2 string) Annotation {
+	w1 = strings.ToLower(w1)
+	w2 = strings.ToLower(w2)
+	if r, ok := annotRanks[w1]; ok {
+		if n, ok := annotNovelties[w2]; ok && n != novBefore {
+			return annotType(r, n)
+		}
+		return NoAnnot
+	}
+	if n, ok := annotNovelties[w1]; ok && n != novAfter {
+		if r, ok := annotRanks[w2]; ok {
+			return annotType(r, n)
+		}
+	}
+	return NoAnnot
+}
+
+func annotType(r annotRank, n annotNovelty) Annotation {
+	switch n {
+	case rev:
+		if r == statRank {
+			return StatRev
+		}
+		return NoAnnot
+	case nud:
+		if r == nomRank {
+			return NomNud
+		}
+		return NoAnnot
+	}
+
+	switch r {
+	case spRank:
+		return SpNov
+	case subspRank:
+		return SubspNov
+	case combRank:
+		return CombNov
+	case nomRank:
+		return NomNov
+	case genRank:
+		return GenNov
+	case famRank:
+		return FamNov
+	case statRank:
+		return StatNov
+	case synRank:
+		return SynNov
+	default:
+		return NoAnnot
+	}
+}
@@ -0,0 +1,21 @@
+package output
+
+// AddCell adds names found in a line of a checklist or in a table cell to
+// the output. Offsets of the names have to refer to the whole text.
+func (o *Output) AddCell(cell Output, row, column int) {
+	idx := len(o.Names)
+	for _, v := range cell.Names {
+		v.Row, v.Column = row, column
+		o.Names = append(o.Names, v)
+	}
+	for _, v := range cell.Relations {
+		v.NameIndex += idx
+		if v.RelatedIndex >= 0 {
+			v.RelatedIndex += idx
+		}
+		o.Relations = append(o.Relations, v)
+	}
+	o.TotalWords += cell.TotalWords
+	o.TotalNameCandidates += cell.TotalNameCandidates
+	o.TotalNames += cell.TotalNames
+}
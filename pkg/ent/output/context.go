@@ -0,0 +1,48 @@
+package output
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gnames/gnfinder/pkg/ent/segment"
+)
+
+// AddContext sets sentences, paragraphs or characters around names as
+// their contexts. In the Words mode names keep only WordsBefore and
+// WordsAfter. Offsets of names have to refer to the text.
+func (o *Output) AddContext(text []rune, m segment.ContextMode, size int) {
+	var spans []segment.Span
+	switch m {
+	case segment.Sentence:
+		spans = segment.Sentences(text)
+	case segment.Paragraph:
+		spans = segment.Paragraphs(text)
+	case segment.Characters:
+	default:
+		return
+	}
+	for i := range o.Names {
+		n := &o.Names[i]
+		var s segment.Span
+		if m == segment.Characters {
+			s = segment.Window(text, n.OffsetStart, n.OffsetEnd, size)
+		} else {
+			s = segment.Enclosing(spans, n.OffsetStart, n.OffsetEnd)
+		}
+		n.Context = contextString(text[s.Start:s.End])
+		n.ContextStart, n.ContextEnd = s.Start, s.End
+		n.ContextNameStart = n.OffsetStart - s.Start
+		n.ContextNameEnd = n.OffsetEnd - s.Start
+	}
+}
+
+// contextString replaces new lines, tabs and other white spaces by spaces
+// one by one, so positions in the context do not change.
+func contextString(context []rune) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, string(context))
+}
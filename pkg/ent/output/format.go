@@ -24,8 +24,49 @@ func (o *Output) Format(f gnfmt.Format) string {
 
 // CSVHeader returns the header string for CSV output format.
 func CSVHeader(withVerification bool, sep rune) string {
-	res := []string{"Index", "Verbatim", "Name", "Start", "End",
-		"OddsLog10", "Cardinality", "AnnotNomenType", "WordsBefore", "WordsAfter"}
+	return csvHeader(withVerification, csvColumns{}, sep)
+}
+
+// csvColumns are optional columns of CSV output.
+type csvColumns struct {
+	// lines are line and column numbers of names.
+	lines bool
+
+	// cells are rows and columns of checklists.
+	cells bool
+
+	// context is the context of names.
+	context bool
+
+	// count is the number of occurrences of aggregated names.
+	count bool
+}
+
+func (o *Output) csvColumns() csvColumns {
+	return csvColumns{
+		lines:   o.WithLinePositions,
+		cells:   o.Checklist != "",
+		context: o.ContextMode != "",
+		count:   o.WithAggregatedNames,
+	}
+}
+
+func csvHeader(withVerification bool, cols csvColumns, sep rune) string {
+	res := []string{"Index", "Verbatim", "Name", "Start", "End"}
+	if cols.cells {
+		res = append(res, "Row", "Column")
+	}
+	if cols.lines {
+		res = append(res, "LineStart", "ColStart", "LineEnd", "ColEnd")
+	}
+	res = append(res, "OddsLog10", "Cardinality", "AnnotNomenType",
+		"WordsBefore", "WordsAfter", "ExpandedName", "Qualifier", "Hybrid")
+	if cols.count {
+		res = append(res, "Count")
+	}
+	if cols.context {
+		res = append(res, "Context")
+	}
 	if withVerification {
 		verif := []string{"VerifMatchType", "VerifSortScore", "VerifEditDistance",
 			"VerifMatchedName", "VerifMatchedCanonical", "VerifTaxonId",
@@ -37,16 +78,17 @@ func CSVHeader(withVerification bool, sep rune) string {
 
 func (o *Output) csvOutput(sep rune) string {
 	res := make([]string, 1, len(o.Names)+1)
-	res[0] = CSVHeader(o.WithVerification, sep)
+	cols := o.csvColumns()
+	res[0] = csvHeader(o.WithVerification, cols, sep)
 	for i := range o.Names {
-		pref := csvRow(o.Names[i], i, sep)
+		pref := csvRow(o.Names[i], i, cols, sep)
 		res = append(res, pref...)
 	}
 
 	return strings.Join(res, "\n")
 }
 
-func csvRow(name Name, i int, sep rune) []string {
+func csvRow(name Name, i int, cols csvColumns, sep rune) []string {
 	var odds string
 	var res []string
 	if name.OddsLog10 > 0 {
@@ -56,10 +98,25 @@ func csvRow(name Name, i int, sep rune) []string {
 	wrdsAfter := strings.Join(name.WordsAfter, ", ")
 	start := strconv.Itoa(name.OffsetStart)
 	end := strconv.Itoa(name.OffsetEnd)
-	s := []string{
-		strconv.Itoa(i), name.Verbatim, name.Name, start,
-		end, odds, strconv.Itoa(name.Cardinality),
-		name.AnnotNomenType, wrdsBefore, wrdsAfter,
+	s := []string{strconv.Itoa(i), name.Verbatim, name.Name, start, end}
+	if cols.cells {
+		s = append(s, strconv.Itoa(name.Row), strconv.Itoa(name.Column))
+	}
+	if cols.lines {
+		s = append(s,
+			strconv.Itoa(name.LineStart), strconv.Itoa(name.ColStart),
+			strconv.Itoa(name.LineEnd), strconv.Itoa(name.ColEnd),
+		)
+	}
+	s = append(s, odds, strconv.Itoa(name.Cardinality),
+		name.AnnotNomenType, wrdsBefore, wrdsAfter, name.ExpandedName,
+		name.Qualifier, name.Hybrid,
+	)
+	if cols.count {
+		s = append(s, strconv.Itoa(name.Count))
+	}
+	if cols.context {
+		s = append(s, name.Context)
 	}
 
 	if name.Verification != nil {
@@ -0,0 +1,18 @@
+package output
+
+import "github.com/gnames/gnfinder/pkg/ent/graph"
+
+// AddGraph builds a co-occurrence graph of found names. Abbreviated names
+// use their expanded forms, so "P. moesta" and "Pardosa moesta" are the
+// same node. Offsets of names have to refer to the text.
+func (o *Output) AddGraph(text []rune, w graph.Window, tokens int) {
+	ms := make([]graph.Mention, len(o.Names))
+	for i, v := range o.Names {
+		name := v.Name
+		if v.ExpandedName != "" {
+			name = v.ExpandedName
+		}
+		ms[i] = graph.Mention{Name: name, Start: v.OffsetStart, End: v.OffsetEnd}
+	}
+	o.Graph = graph.Build(text, ms, w, tokens)
+}
@@ -0,0 +1,223 @@
+package output
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Hierarchy is a tree of taxa assembled from headings of a document, for
+// example of a monograph with headings "Family Lycosidae", "Genus Pardosa"
+// and "Pardosa moesta Banks, 1892".
+type Hierarchy struct {
+	// Taxa are taxa of the headings in the order of the text.
+	Taxa []HeadingTaxon `json:"taxa"`
+}
+
+// HeadingTaxon is a taxon of a heading.
+type HeadingTaxon struct {
+	// Name is the name of the taxon.
+	Name string `json:"name"`
+
+	// Rank of the taxon is taken from a rank word of the heading, from the
+	// ending of a uninomial, or from the number of words of the name.
+	// Uninomials without other clues are considered genera.
+	Rank string `json:"rank"`
+
+	// ParentIndex is the index of the parent taxon in Taxa. It is -1 for
+	// taxa without a parent.
+	ParentIndex int `json:"parentIndex"`
+
+	// OffsetStart is the start of the name in the heading.
+	OffsetStart int `json:"start"`
+
+	// OffsetEnd is the end of the name in the heading.
+	OffsetEnd int `json:"end"`
+}
+
+// rankLevels keep the order of ranks from the highest to the lowest.
+var rankLevels = map[string]int{
+	"kingdom":      1,
+	"phylum":       2,
+	"class":        3,
+	"subclass":     4,
+	"order":        5,
+	"suborder":     6,
+	"superfamily":  7,
+	"family":       8,
+	"subfamily":    9,
+	"tribe":        10,
+	"subtribe":     11,
+	"genus":        12,
+	"subgenus":     13,
+	"species":      14,
+	"subspecies":   15,
+	"infraspecies": 15,
+}
+
+// rankWords are English and German words that precede names in headings.
+var rankWords = map[string]string{
+	"kingdom":      "kingdom",
+	"phylum":       "phylum",
+	"division":     "phylum",
+	"class":        "class",
+	"subclass":     "subclass",
+	"order":        "order",
+	"suborder":     "suborder",
+	"superfamily":  "superfamily",
+	"family":       "family",
+	"subfamily":    "subfamily",
+	"tribe":        "tribe",
+	"subtribe":     "subtribe",
+	"genus":        "genus",
+	"subgenus":     "subgenus",
+	"species":      "species",
+	"subspecies":   "subspecies",
+	"reich":        "kingdom",
+	"stamm":        "phylum",
+	"klasse":       "class",
+	"unterklasse":  "subclass",
+	"ordnung":      "order",
+	"unterordnung": "suborder",
+	"überfamilie":  "superfamily",
+	"familie":      "family",
+	"unterfamilie": "subfamily",
+	"tribus":       "tribe",
+	"gattung":      "genus",
+	"untergattung": "subgenus",
+	"art":          "species",
+	"unterart":     "subspecies",
+}
+
+// authorWords are lower-case words allowed in authorships and
+// nomenclatural annotations after names in headings.
+var authorWords = map[string]struct{}{
+	"and": {}, "et": {}, "in": {}, "ex": {}, "de": {}, "da": {}, "von": {},
+	"van": {}, "der": {}, "den": {}, "la": {}, "le": {}, "du": {},
+	"nov": {}, "n": {}, "sp": {}, "gen": {}, "fam": {}, "comb": {},
+	"stat": {}, "syn": {}, "sensu": {}, "auct": {}, "emend": {}, "nom": {},
+}
+
+// maxHeadingSuffix is the maximal number of words after a name in
+// a heading.
+const maxHeadingSuffix = 6
+
+// numberRe matches numbers of headings like "1.", "2.1", "IV." or "a)".
+var numberRe = regexp.MustCompile(`^(?:\d+(?:\.\d+)*|[IVXLC]+|[a-z])[.)]?$`)
+
+// AddHierarchy finds names that are headings of the text, builds a tree of
+// their taxa, and attributes other names to the enclosing heading taxon.
+// Offsets of names have to refer to the text.
+func (o *Output) AddHierarchy(text []rune) {
+	var taxa []HeadingTaxon
+	var stack []int
+	for i := range o.Names {
+		n := &o.Names[i]
+		rank, ok := headingRank(text, n)
+		if !ok {
+			if len(stack) > 0 {
+				n.HeadingTaxon = taxa[stack[len(stack)-1]].Name
+			}
+			continue
+		}
+
+		level := rankLevels[rank]
+		for len(stack) > 0 && rankLevels[taxa[stack[len(stack)-1]].Rank] >= level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := -1
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+			n.HeadingTaxon = taxa[parent].Name
+		}
+		n.IsHeading = true
+		taxa = append(taxa, HeadingTaxon{
+			Name:        n.Name,
+			Rank:        rank,
+			ParentIndex: parent,
+			OffsetStart: n.OffsetStart,
+			OffsetEnd:   n.OffsetEnd,
+		})
+		stack = append(stack, len(taxa)-1)
+	}
+	if len(taxa) > 0 {
+		o.Hierarchy = &Hierarchy{Taxa: taxa}
+	}
+}
+
+// headingRank checks if a name is a heading, and returns its rank. A
+// heading is a line that contains only the name with an optional number
+// and a rank word before it, and an optional authorship after it.
+func headingRank(text []rune, n *Name) (string, bool) {
+	words := strings.Fields(n.Verbatim)
+	if n.InTable || len(words) == 0 || strings.HasSuffix(words[0], ".") {
+		return "", false
+	}
+	start, end := n.OffsetStart, n.OffsetEnd
+	for start > 0 && text[start-1] != '\n' && text[start-1] != '\f' {
+		start--
+	}
+	for end < len(text) && text[end] != '\n' && text[end] != '\f' {
+		end++
+	}
+
+	prefix := strings.Fields(string(text[start:n.OffsetStart]))
+	if len(prefix) > 0 && numberRe.MatchString(prefix[0]) {
+		prefix = prefix[1:]
+	}
+	var rank string
+	switch len(prefix) {
+	case 0:
+	case 1:
+		var ok bool
+		rank, ok = rankWords[strings.ToLower(strings.Trim(prefix[0], ".:"))]
+		if !ok {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	suffix := strings.Fields(string(text[n.OffsetEnd:end]))
+	if len(suffix) > maxHeadingSuffix {
+		return "", false
+	}
+	for _, v := range suffix {
+		if !isAuthorWord(v) {
+			return "", false
+		}
+	}
+
+	if rank != "" {
+		return rank, true
+	}
+	switch n.Cardinality {
+	case 2:
+		return "species", true
+	case 3:
+		return "infraspecies", true
+	}
+	if _, ok := rankLevels[n.Rank]; ok {
+		return n.Rank, true
+	}
+	return "genus", true
+}
+
+// isAuthorWord checks if a word might be a part of an authorship, a year or
+// a nomenclatural annotation.
+func isAuthorWord(w string) bool {
+	w = strings.Trim(w, "()[],.;:&")
+	if w == "" {
+		return true
+	}
+	for _, r := range w {
+		if unicode.IsDigit(r) {
+			return true
+		}
+	}
+	if unicode.IsUpper([]rune(w)[0]) {
+		return true
+	}
+	_, ok := authorWords[strings.ToLower(w)]
+	return ok
+}
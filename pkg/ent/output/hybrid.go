@@ -0,0 +1,104 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/gnames/gnfinder/pkg/ent/token"
+)
+
+// hybridName adds the hybrid sign to names of nothogenera and
+// nothospecies, for example "× Triticosecale", "Salix × rubens".
+func hybridName(ts []token.TokenSN, i int, name *Name, text []rune) {
+	u := ts[i]
+	switch u.Features().Hybrid {
+	case token.Nothogenus:
+		name.Name = "× " + name.Name
+		if !u.Features().HybridPrefix {
+			// the hybrid sign is a separate token.
+			name.OffsetStart = ts[i-1].Start()
+			name.Verbatim = verbatim(text[name.OffsetStart:name.OffsetEnd])
+		}
+	case token.Nothospecies:
+		if name.Cardinality < 2 {
+			return
+		}
+		words := strings.SplitN(name.Name, " ", 2)
+		name.Name = words[0] + " × " + words[1]
+	default:
+		return
+	}
+	name.Hybrid = u.Features().Hybrid.String()
+}
+
+// hybridFormula checks if a name that starts at the token i is followed by
+// the hybrid sign and another name, for example
+// "Mentha aquatica × M. spicata". If it is, the names are merged into
+// a hybrid formula. The function returns the index of the first token
+// after the formula, or 0 if the formula was not found.
+func hybridFormula(
+	ts []token.TokenSN,
+	i int,
+	name *Name,
+	text []rune,
+) int {
+	if name.Hybrid != "" {
+		return 0
+	}
+	j := i + lastIndex(ts[i]) + 1
+	if j+1 >= len(ts) || !token.IsHybridSign(ts[j]) ||
+		ts[j+1].Decision() == token.NotName {
+		return 0
+	}
+
+	k := j + 1
+	f := ts[k].Features()
+	// the sign between parents can be mistaken for a nothogenus sign.
+	isNotho := f.Hybrid == token.Nothogenus && !f.HybridPrefix
+	if f.Hybrid != token.NoHybrid && !isNotho {
+		return 0
+	}
+	name2 := tokensToName(ts[k:token.UpperIndex(k, len(ts))], text)
+	parent1 := name.Name
+	parent2 := expandParent(parent1, name2.Name)
+
+	name.Name = parent1 + " × " + parent2
+	name.HybridParents = []string{parent1, parent2}
+	name.Hybrid = token.HybridFormula.String()
+	name.OffsetEnd = name2.OffsetEnd
+	name.Verbatim = verbatim(text[name.OffsetStart:name.OffsetEnd])
+	return k + lastIndex(ts[k]) + 1
+}
+
+// lastIndex returns the index of the last token of a name relative to its
+// first token.
+func lastIndex(u token.TokenSN) int {
+	switch u.Decision().Cardinality() {
+	case 2:
+		return u.Indices().Species
+	case 3:
+		return u.Indices().Infraspecies
+	default:
+		return 0
+	}
+}
+
+// expandParent replaces abbreviated genus of the second parent of
+// a hybrid formula with the genus of the first parent, if they match.
+func expandParent(parent1, parent2 string) string {
+	words1 := strings.SplitN(parent1, " ", 2)
+	words2 := strings.SplitN(parent2, " ", 2)
+	if len(words2) < 2 {
+		return parent2
+	}
+	abbr, ok := genusAbbr(words2[0])
+	if !ok || !strings.HasPrefix(words1[0], abbr) {
+		return parent2
+	}
+	return words1[0] + " " + words2[1]
+}
+
+// trimHybridSign removes the hybrid sign from the start of a nothogenus
+// name.
+func trimHybridSign(name string) string {
+	return strings.TrimPrefix(name, "× ")
+}
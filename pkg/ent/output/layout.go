@@ -0,0 +1,122 @@
+package output
+
+import (
+	"math"
+	"sort"
+)
+
+// Layout contains pages of a document, for example of a PDF file, and
+// positions of characters of its text on the pages.
+type Layout struct {
+	// Pages are pages of the document in the order of the text.
+	Pages []Page
+
+	// Boxes are bounding boxes of every character of the text. Characters
+	// with unknown position, like spaces added between words, have empty
+	// boxes. If Boxes are empty, names do not get bounding boxes.
+	Boxes []Box
+}
+
+// Page is a page of a document.
+type Page struct {
+	// Number of the page, it starts from 1.
+	Number int
+
+	// OffsetStart is the start of the page in the text.
+	OffsetStart int
+
+	// OffsetEnd is the end of the page in the text.
+	OffsetEnd int
+}
+
+// Box is a rectangle on a page in points (1/72 of an inch). The origin
+// is at the bottom left corner of the page.
+type Box struct {
+	X0 float64 `json:"x0"`
+	Y0 float64 `json:"y0"`
+	X1 float64 `json:"x1"`
+	Y1 float64 `json:"y1"`
+}
+
+// BoundingBox is a rectangle that contains a name or a part of it on
+// a page.
+type BoundingBox struct {
+	// Page is the number of the page.
+	Page int `json:"page"`
+	Box
+}
+
+// IsEmpty is true if the position of the box is unknown.
+func (b Box) IsEmpty() bool {
+	return b == Box{}
+}
+
+// union returns a box that contains both boxes.
+func (b Box) union(b2 Box) Box {
+	return Box{
+		X0: math.Min(b.X0, b2.X0),
+		Y0: math.Min(b.Y0, b2.Y0),
+		X1: math.Max(b.X1, b2.X1),
+		Y1: math.Max(b.Y1, b2.Y1),
+	}
+}
+
+// sameLine checks if two boxes overlap vertically.
+func (b Box) sameLine(b2 Box) bool {
+	return b.Y0 < b2.Y1 && b2.Y0 < b.Y1
+}
+
+// AddLayout sets pages and bounding boxes of names. It uses offsets of the
+// names in UTF-8 characters, so it has to run before conversion of
+// offsets to other units.
+func (o *Output) AddLayout(l Layout) {
+	if len(l.Pages) == 0 {
+		return
+	}
+	o.PagesNum = len(l.Pages)
+	for i := range o.Names {
+		n := &o.Names[i]
+		n.Page = l.page(n.OffsetStart)
+		n.BoundingBoxes = l.boundingBoxes(n.OffsetStart, n.OffsetEnd)
+	}
+}
+
+// page returns the number of the page that contains the offset.
+func (l Layout) page(offset int) int {
+	i := sort.Search(len(l.Pages), func(i int) bool {
+		return l.Pages[i].OffsetEnd > offset
+	})
+	if i == len(l.Pages) {
+		return 0
+	}
+	return l.Pages[i].Number
+}
+
+// boundingBoxes returns boxes around parts of a text between start and
+// end. Every line of the text gets its own box.
+func (l Layout) boundingBoxes(start, end int) []BoundingBox {
+	if len(l.Boxes) == 0 {
+		return nil
+	}
+	var res []BoundingBox
+	var cur BoundingBox
+	for i := start; i < end && i < len(l.Boxes); i++ {
+		b := l.Boxes[i]
+		if b.IsEmpty() {
+			continue
+		}
+		p := l.page(i)
+		if !cur.IsEmpty() && cur.Page == p && cur.sameLine(b) {
+			cur.Box = cur.union(b)
+			continue
+		}
+		if !cur.IsEmpty() {
+			res = append(res, cur)
+		}
+		cur = BoundingBox{Page: p, Box: b}
+	}
+	if !cur.IsEmpty() {
+		res = append(res, cur)
+	}
+	return res
+}
@@ -6,6 +6,10 @@ import (
 
 	boutput "github.com/gnames/bayes/ent/output"
 	"github.com/gnames/gnfinder/pkg/config"
+	"github.com/gnames/gnfinder/pkg/ent/graph"
+	"github.com/gnames/gnfinder/pkg/ent/markup"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/gnames/gnfinder/pkg/ent/segment"
 	"github.com/gnames/gnfinder/pkg/ent/token"
 	vlib "github.com/gnames/gnlib/ent/verifier"
 )
@@ -15,6 +19,17 @@ type Output struct {
 	Meta      `json:"metadata"`
 	InputText string `json:"inputText,omitempty"`
 	Names     []Name `json:"names"`
+
+	// Relations link found names according to synonymy and other
+	// statements in the text, for example "Aus bus = Cus dus". For unique
+	// names, indices of relations refer to unique names.
+	Relations []Relation `json:"relations,omitempty"`
+
+	// Hierarchy is a tree of taxa from headings of the document.
+	Hierarchy *Hierarchy `json:"hierarchy,omitempty"`
+
+	// Graph is a co-occurrence graph of found names.
+	Graph *graph.Graph `json:"graph,omitempty"`
 }
 
 // Meta contains meta-information of name-finding result.
@@ -48,12 +63,28 @@ type Meta struct {
 	// a name-string candidate.
 	WordsAround int `json:"wordsAround"`
 
+	// ContextMode is a way to find contexts of names: "sentence",
+	// "paragraph" or "characters". It is empty for the default WordsAround
+	// context.
+	ContextMode string `json:"contextMode,omitempty"`
+
+	// ContextSize is the number of characters before and after a name in
+	// the "characters" context mode.
+	ContextSize int `json:"contextSize,omitempty"`
+
 	// Language setting used by the name-finding algorithm.
 	Language string `json:"language"`
 
 	// LanguageDetected automatically for the text.
 	LanguageDetected string `json:"languageDetected,omitempty"`
 
+	// Markup is a markup language of the input text.
+	Markup string `json:"markup,omitempty"`
+
+	// ItalicsOdds is the fixed odds ratio of italicized words of marked-up
+	// texts.
+	ItalicsOdds float64 `json:"italicsOdds,omitempty"`
+
 	// WithAllMatches is true if all verifcation results are shown.
 	WithAllMatches bool `json:"withAllMatches,omitempty"`
 
@@ -65,6 +96,17 @@ type Meta struct {
 	// of every occurance of a name.
 	WithUniqueNames bool `json:"withUniqueNames,omitempty"`
 
+	// WithAggregatedNames is true when unique names have counts, offsets
+	// and verbatim variants of all their occurrences.
+	WithAggregatedNames bool `json:"withAggregatedNames,omitempty"`
+
+	// WithAllCaps is true if names written in upper case are detected.
+	WithAllCaps bool `json:"withAllCaps,omitempty"`
+
+	// UniqueNamesOrder is sorting of unique names: "alphabetical",
+	// "frequency" or "appearance".
+	UniqueNamesOrder string `json:"uniqueNamesOrder,omitempty"`
+
 	// WithBayes use of bayes during name-finding
 	WithBayes bool `json:"withBayes,omitempty"`
 
@@ -72,16 +114,76 @@ type Meta struct {
 	// scientific names in the text.
 	WithOddsAdjustment bool `json:"withOddsAdjustment,omitempty"`
 
+	// OffsetUnit is the unit of start and end positions: "runes" (UTF-8
+	// characters), "bytes" or "utf16" (UTF-16 code units).
+	OffsetUnit string `json:"offsetUnit,omitempty"`
+
 	// WithPositionInBytes names get start/enc positionx in bytes
 	// instead of UTF-8 chars.
+	//
+	// Deprecated: use OffsetUnit instead.
 	WithPositionInBytes bool `json:"withPositionInBytes,omitempty"`
 
+	// UnicodeForm is a Unicode normalization form applied to the text
+	// before name-finding.
+	UnicodeForm string `json:"unicodeForm,omitempty"`
+
+	// WithLinePositions is true if names get line and column numbers.
+	WithLinePositions bool `json:"withLinePositions,omitempty"`
+
 	// WithVerification is true if results are checked by verification service.
 	WithVerification bool `json:"withVerification,omitempty"`
 
+	// WithFuzzyMatch is true if misspelled words are matched to the
+	// dictionaries.
+	WithFuzzyMatch bool `json:"withFuzzyMatch,omitempty"`
+
+	// WithHistoricText is true if historic text normalization and
+	// correction of OCR errors are used.
+	WithHistoricText bool `json:"withHistoricText,omitempty"`
+
+	// WithHierarchy is true if a tree of taxa is assembled from
+	// headings of the document.
+	WithHierarchy bool `json:"withHierarchy,omitempty"`
+
+	// WithLayoutCleanup is true if page numbers, running headers and
+	// hyphenation were removed before name-finding.
+	WithLayoutCleanup bool `json:"withLayoutCleanup,omitempty"`
+
+	// ExcludedSections are sections of the document where names are not
+	// reported.
+	ExcludedSections []string `json:"excludedSections,omitempty"`
+
+	// Checklist is a mode of name-finding in species lists and tables,
+	// where every line or table cell is a separate document.
+	Checklist string `json:"checklist,omitempty"`
+
+	// ChecklistColumn is the only column of a table used for
+	// name-finding.
+	ChecklistColumn int `json:"checklistColumn,omitempty"`
+
+	// PageMarker is a regular expression for lines that separate pages
+	// used by the layout cleanup.
+	PageMarker string `json:"pageMarker,omitempty"`
+
+	// WithTables is true if tables in running texts are detected.
+	WithTables bool `json:"withTables,omitempty"`
+
+	// WithTypeMaterial is true if type material and specimens are extracted
+	// for names with nomenclatural annotations.
+	WithTypeMaterial bool `json:"withTypeMaterial,omitempty"`
+
 	// WithLanguageDetection sets automatic language determination.
 	WithLanguageDetection bool `json:"withLanguageDetection,omitempty"`
 
+	// PagesNum is the number of pages of a paged document, for example
+	// PDF.
+	PagesNum int `json:"pagesNum,omitempty"`
+
+	// MaskedRanges are running headers and footers, page numbers and page
+	// markers removed from the text by the layout cleanup.
+	MaskedRanges []MaskedRange `json:"maskedRanges,omitempty"`
+
 	// TotalWords is a number of 'normalized' words in the text
 	TotalWords int `json:"totalWords"`
 
@@ -114,6 +216,15 @@ type Meta struct {
 	StatsNamesNum int `json:"statsNamesNum,omitempty"`
 }
 
+// Occurrence is the position of a name in the text.
+type Occurrence struct {
+	// OffsetStart is the start of the occurrence.
+	OffsetStart int `json:"start"`
+
+	// OffsetEnd is the end of the occurrence.
+	OffsetEnd int `json:"end"`
+}
+
 // Kingdom contains names resolved to it and their percentage.
 type Kingdom struct {
 	NamesNumber     int     `json:"namesNumber"`
@@ -140,6 +251,26 @@ type Name struct {
 	// Name is a normalized version of a name.
 	Name string `json:"name"`
 
+	// Corrected is true if the name was changed by historic text
+	// normalization or by correction of OCR errors. The original form of
+	// the name is kept in Verbatim.
+	Corrected bool `json:"corrected,omitempty"`
+
+	// SuggestedName is a version of the name where misspelled words are
+	// substituted by the closest genera or epithets from the dictionaries.
+	// It is set only if fuzzy matching is used.
+	SuggestedName string `json:"suggestedName,omitempty"`
+
+	// ExpandedName is a version of the name where an abbreviated genus
+	// is replaced by the full genus found earlier in the same document.
+	// For example "P. moesta" becomes "Pardosa moesta".
+	ExpandedName string `json:"expandedName,omitempty"`
+
+	// ExpandedAmbiguous is true if more than one genus found earlier in the
+	// document matched the abbreviation. In such cases the most recent genus
+	// is used for ExpandedName.
+	ExpandedAmbiguous bool `json:"expandedAmbiguous,omitempty"`
+
 	// Decision about the quality of name detection.
 	Decision token.Decision `json:"-"`
 
@@ -158,18 +289,125 @@ type Name struct {
 	// OffsetEnd is the end of the name on a page.
 	OffsetEnd int `json:"end"`
 
-	// AnnotNomen is a nomenclatural annotation for new species or combination.
+	// LineStart is the line where the name starts, it starts from 1.
+	LineStart int `json:"lineStart,omitempty"`
+
+	// ColStart is the column of the first character of the name in
+	// the LineStart line. Columns are counted in UTF-8 characters and
+	// start from 1.
+	ColStart int `json:"colStart,omitempty"`
+
+	// LineEnd is the line where the name ends.
+	LineEnd int `json:"lineEnd,omitempty"`
+
+	// ColEnd is the column right after the last character of the name in
+	// the LineEnd line.
+	ColEnd int `json:"colEnd,omitempty"`
+
+	// Page is the number of the page where the name starts. It is set for
+	// paged documents, for example PDF files, and for texts with pages
+	// separated by form feed characters.
+	Page int `json:"page,omitempty"`
+
+	// Section is the section of the document where the name was found, for
+	// example "references" or "key".
+	Section string `json:"section,omitempty"`
+
+	// IsHeading is true if the name is a heading of the document, for
+	// example "Genus Pardosa" or "Pardosa moesta Banks, 1892".
+	IsHeading bool `json:"isHeading,omitempty"`
+
+	// HeadingTaxon is the name of the taxon of the enclosing heading. For
+	// headings it is the name of the parent taxon.
+	HeadingTaxon string `json:"headingTaxon,omitempty"`
+
+	// InTable is true if the name was found in a table-like region of
+	// a running text.
+	InTable bool `json:"inTable,omitempty"`
+
+	// Row is the number of the line or of the table row of a checklist
+	// where the name was found. It starts from 1.
+	Row int `json:"row,omitempty"`
+
+	// Column is the number of the table column where the name was found.
+	// It starts from 1.
+	Column int `json:"column,omitempty"`
+
+	// BoundingBoxes are rectangles around the name on pages of a PDF
+	// document. A name that is split between lines has several boxes.
+	BoundingBoxes []BoundingBox `json:"boundingBoxes,omitempty"`
+
+	// AnnotNomen is a verbatim nomenclatural annotation for new species,
+	// combination etc. The annotation can be placed after or before
+	// the name.
 	AnnotNomen string `json:"annotationNomen,omitempty"`
 
-	// AnnotNomenType is normalized nomenclatural annotation.
+	// AnnotNomenType is normalized nomenclatural annotation. Its values
+	// are given by the Annotation type.
 	AnnotNomenType string `json:"annotationNomenType,omitempty"`
 
+	// AnnotNomenStart is the start of the annotation in the text.
+	AnnotNomenStart int `json:"annotationNomenStart,omitempty"`
+
+	// AnnotNomenEnd is the end of the annotation in the text.
+	AnnotNomenEnd int `json:"annotationNomenEnd,omitempty"`
+
+	// Hybrid marks hybrid names. It can be "NOTHOGENUS" (× Triticosecale),
+	// "NOTHOSPECIES" (Salix × rubens) or "HYBRID_FORMULA"
+	// (Mentha aquatica × Mentha spicata).
+	Hybrid string `json:"hybrid,omitempty"`
+
+	// HybridParents are the parent names of a hybrid formula.
+	HybridParents []string `json:"hybridParents,omitempty"`
+
+	// TypeMaterial contains mentions of type specimens and examined
+	// material found after a name with a nomenclatural annotation.
+	TypeMaterial []TypeMaterial `json:"typeMaterial,omitempty"`
+
+	// Rank is a rank of a uninomial inferred from its standardized ending,
+	// for example "family" for "Lycosidae" or "order" for "Rosales".
+	Rank string `json:"rank,omitempty"`
+
+	// Qualifier is a normalized open nomenclature qualifier of the name,
+	// for example "cf.", "aff.", "sp.", "spp.", "sp. indet.".
+	Qualifier string `json:"qualifier,omitempty"`
+
 	// WordsBefore are words that happened before the name.
 	WordsBefore []string `json:"wordsBefore,omitempty"`
 
 	// WordsAfter are words that happened right after the name.
 	WordsAfter []string `json:"wordsAfter,omitempty"`
 
+	// Context is the sentence, the paragraph or the characters around the
+	// name, depending on ContextMode. It is taken from the text used for
+	// name-finding, so it has no markup, and white spaces are replaced by
+	// spaces.
+	Context string `json:"context,omitempty"`
+
+	// ContextStart is the start of the context in the text.
+	ContextStart int `json:"contextStart"`
+
+	// ContextEnd is the end of the context in the text.
+	ContextEnd int `json:"contextEnd,omitempty"`
+
+	// ContextNameStart is the start of the name in the Context string.
+	ContextNameStart int `json:"contextNameStart"`
+
+	// ContextNameEnd is the end of the name in the Context string.
+	ContextNameEnd int `json:"contextNameEnd,omitempty"`
+
+	// Count is the number of occurrences of an aggregated unique name.
+	Count int `json:"count,omitempty"`
+
+	// Occurrences are start and end offsets of all occurrences of an
+	// aggregated unique name in the order of the text. OffsetStart and
+	// OffsetEnd of the name are offsets of the first occurrence.
+	Occurrences []Occurrence `json:"occurrences,omitempty"`
+
+	// VerbatimVariants are different verbatim forms of an aggregated unique
+	// name in the order of their first appearance.
+	VerbatimVariants []string `json:"verbatimVariants,omitempty"`
+
 	// Verification gives results of verification process of the name.
 	Verification *vlib.Name `json:"verification,omitempty"`
 }
@@ -224,16 +462,47 @@ func newOutput(
 		WithAllMatches:      cfg.WithAllMatches,
 		WithAmbiguousNames:  cfg.WithAmbiguousNames,
 		WithUniqueNames:     cfg.WithUniqueNames,
+		WithAggregatedNames: cfg.WithAggregatedNames,
+		WithAllCaps:         cfg.WithAllCaps,
 		WithBayes:           cfg.WithBayes,
 		WithOddsAdjustment:  cfg.WithOddsAdjustment,
 		WithVerification:    cfg.WithVerification,
+		WithFuzzyMatch:      cfg.WithFuzzyMatch,
+		WithHistoricText:    cfg.WithHistoricText,
+		WithTables:          cfg.WithTables,
+		WithTypeMaterial:    cfg.WithTypeMaterial,
+		WithLinePositions:   cfg.WithLinePositions,
+		WithLayoutCleanup:   cfg.WithLayoutCleanup,
+		WithHierarchy:       cfg.WithHierarchy,
 		WordsAround:         cfg.TokensAround,
 		Language:            cfg.Language.String(),
 		LanguageDetected:    cfg.LanguageDetected,
+		Markup:              cfg.Markup.String(),
+		Checklist:           cfg.Checklist.String(),
+		ChecklistColumn:     cfg.ChecklistColumn,
+		ExcludedSections:    sectionStrings(cfg.ExcludeSections),
+		OffsetUnit:          cfg.OffsetUnit.String(),
+		WithPositionInBytes: cfg.OffsetUnit == offset.Byte,
+		UnicodeForm:         cfg.UnicodeForm.String(),
 		TotalWords:          len(ts),
 		TotalNameCandidates: candidatesNum(ts),
 		TotalNames:          len(names),
 	}
+	if cfg.WithLayoutCleanup {
+		meta.PageMarker = cfg.PageMarker
+	}
+	if cfg.Markup != markup.NoMarkup && cfg.WithBayes {
+		meta.ItalicsOdds = cfg.ItalicsOdds
+	}
+	if cfg.WithUniqueNames {
+		meta.UniqueNamesOrder = cfg.UniqueNamesOrder.String()
+	}
+	if cfg.ContextMode != segment.Words {
+		meta.ContextMode = cfg.ContextMode.String()
+	}
+	if cfg.ContextMode == segment.Characters {
+		meta.ContextSize = cfg.ContextSize
+	}
 	if !cfg.WithAmbiguousNames {
 		names = FilterNames(names, genera)
 	}
@@ -241,7 +510,7 @@ func newOutput(
 	if !cfg.WithBayesOddsDetails || cfg.WithOddsAdjustment {
 		postprocessNames(names, meta.TotalNameCandidates, cfg)
 	}
-	o := Output{Meta: meta, Names: names}
+	o := Output{Meta: meta, Names: names, Relations: nameRelations(ts, names)}
 	o.WithLanguageDetection = o.LanguageDetected != ""
 
 	return o
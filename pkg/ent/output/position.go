@@ -0,0 +1,90 @@
+package output
+
+import (
+	"sort"
+	"strings"
+)
+
+// AddLinePositions sets line and column numbers of the start and the end
+// of names. It uses offsets of the names in UTF-8 characters, so it has to
+// run before conversion of offsets to other units.
+func (o *Output) AddLinePositions(text []rune) {
+	lines := lineStarts(text)
+	for i := range o.Names {
+		n := &o.Names[i]
+		n.LineStart, n.ColStart = lineColumn(lines, n.OffsetStart)
+		n.LineEnd, n.ColEnd = lineColumn(lines, n.OffsetEnd)
+	}
+}
+
+// MaskedRange is a part of the text removed before name-finding as an
+// artifact of page layout.
+type MaskedRange struct {
+	// Kind of the artifact: "header", "footer", "pageNumber" or
+	// "pageMarker".
+	Kind string `json:"kind"`
+
+	// OffsetStart is the start of the range.
+	OffsetStart int `json:"start"`
+
+	// OffsetEnd is the end of the range.
+	OffsetEnd int `json:"end"`
+}
+
+// AddPages sets page numbers of names in texts where pages are separated
+// by form feed characters, for example in texts extracted from PDF files,
+// or by page markers masked by the layout cleanup. If there are page
+// markers, form feeds are ignored. Texts without page breaks are not
+// changed.
+func (o *Output) AddPages(text []rune) {
+	breaks := o.pageMarkers(text)
+	if len(breaks) == 0 {
+		for i, r := range text {
+			if r == '\f' {
+				breaks = append(breaks, i)
+			}
+		}
+	}
+	if len(breaks) == 0 {
+		return
+	}
+	o.PagesNum = len(breaks) + 1
+	for i := range o.Names {
+		n := &o.Names[i]
+		n.Page = sort.SearchInts(breaks, n.OffsetStart) + 1
+	}
+}
+
+// pageMarkers returns starts of masked page markers. A marker at the start
+// of the text does not start a new page.
+func (o *Output) pageMarkers(text []rune) []int {
+	var res []int
+	for _, v := range o.MaskedRanges {
+		if v.Kind != "pageMarker" {
+			continue
+		}
+		if len(res) == 0 &&
+			strings.TrimSpace(string(text[:v.OffsetStart])) == "" {
+			continue
+		}
+		res = append(res, v.OffsetStart)
+	}
+	return res
+}
+
+// lineStarts returns offsets where lines of the text start.
+func lineStarts(text []rune) []int {
+	res := []int{0}
+	for i, r := range text {
+		if r == '\n' {
+			res = append(res, i+1)
+		}
+	}
+	return res
+}
+
+// lineColumn converts an offset to 1-based line and column numbers.
+func lineColumn(lines []int, offset int) (int, int) {
+	i := sort.SearchInts(lines, offset+1) - 1
+	return i + 1, offset - lines[i] + 1
+}
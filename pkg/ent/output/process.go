@@ -8,48 +8,61 @@ import (
 
 	boutput "github.com/gnames/bayes/ent/output"
 	"github.com/gnames/gnfinder/pkg/config"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
 	"github.com/gnames/gnfinder/pkg/ent/token"
 	vlib "github.com/gnames/gnlib/ent/verifier"
 	"github.com/gnames/gnstats/ent/stats"
 )
 
-var rtb map[int]int
-
 // TokensToOutput takes tagged tokens and assembles output out of them.
 func TokensToOutput(
 	ts []token.TokenSN,
 	text []rune,
 	version string,
 	cfg config.Config) Output {
-	// map rune number to byte number
-	if cfg.WithPositionInBytes {
-		populateBytesMap(text)
-	}
-
 	var names []Name
+	var next, from int
 	genera := make(map[string]struct{})
 	for i := range ts {
 		u := ts[i]
-		if u.Decision() == token.NotName {
+		if i < next || u.Decision() == token.NotName {
 			continue
 		}
-		name := tokensToName(ts[i:token.UpperIndex(i, len(ts))], text, cfg)
+		name := tokensToName(ts[i:token.UpperIndex(i, len(ts))], text)
+		hybridName(ts, i, &name, text)
 		name.Odds = calculateOdds(name.OddsDetails)
 		if name.Odds == 0.0 || name.Odds > 1.0 ||
 			name.Decision == token.PossibleUninomial {
-			getTokensAround(ts, i, &name, cfg.TokensAround)
+			next = hybridFormula(ts, i, &name, text)
+			end := next
+			if end == 0 {
+				end = i + lastIndex(u) + 1
+			}
+			getTokensAround(ts, i, end, &name, cfg.TokensAround)
+			from = annotNomen(ts, text, i, from, &name)
 			if name.Decision == token.Binomial || name.Decision == token.Trinomial {
 				genera[getGenus(name)] = struct{}{}
 			}
 			names = append(names, name)
 		}
 	}
+	expandAbbreviations(names)
+	if cfg.WithTypeMaterial {
+		typeMaterials(ts, names)
+	}
 	out := newOutput(names, genera, ts, version, cfg)
+	out.AddPages(text)
+	if cfg.WithLinePositions {
+		out.AddLinePositions(text)
+	}
+	if cfg.OffsetUnit != offset.Rune {
+		out.ConvertOffsets(text, cfg.OffsetUnit)
+	}
 	return out
 }
 
 func getGenus(name Name) string {
-	words := strings.SplitN(name.Name, " ", 2)
+	words := strings.SplitN(trimHybridSign(name.Name), " ", 2)
 	if len(words) > 1 {
 		return words[0]
 	}
@@ -68,123 +81,86 @@ func calculateOdds(det boutput.OddsDetails) float64 {
 	return res
 }
 
-func populateBytesMap(text []rune) {
-	rtb = make(map[int]int)
-	bytes := 0
-	for i := range text {
-		rtb[i] = bytes
-		bytes += len(string(text[i]))
+// ConvertOffsets converts offsets of the output from the number of UTF-8
+// characters to the given unit, for example to bytes or UTF-16 code units.
+func (o *Output) ConvertOffsets(text []rune, u offset.Unit) {
+	conv := offset.Converter(text, u)
+	o.MapOffsets(conv, conv)
+	o.OffsetUnit = u.String()
+	o.WithPositionInBytes = u == offset.Byte
+	// offsets of names in contexts are converted by their contexts.
+	for i := range o.Names {
+		n := &o.Names[i]
+		if n.Context == "" {
+			continue
+		}
+		conv = offset.Converter([]rune(n.Context), u)
+		n.ContextNameStart = conv(n.ContextNameStart)
+		n.ContextNameEnd = conv(n.ContextNameEnd)
+	}
+}
+
+// MapOffsets changes start and end offsets of names, contexts,
+// annotations, type material, relations, headings and masked ranges using
+// given functions. Offsets of names in their contexts do not change.
+// It is used when names were found in a text that differs from the
+// original one, for example in a text without markup.
+func (o *Output) MapOffsets(start, end func(int) int) {
+	for i := range o.Names {
+		n := &o.Names[i]
+		n.OffsetStart, n.OffsetEnd = start(n.OffsetStart), end(n.OffsetEnd)
+		if n.Context != "" {
+			n.ContextStart, n.ContextEnd = start(n.ContextStart), end(n.ContextEnd)
+		}
+		if n.AnnotNomen != "" {
+			n.AnnotNomenStart = start(n.AnnotNomenStart)
+			n.AnnotNomenEnd = end(n.AnnotNomenEnd)
+		}
+		for j := range n.TypeMaterial {
+			tm := &n.TypeMaterial[j]
+			tm.OffsetStart, tm.OffsetEnd = start(tm.OffsetStart), end(tm.OffsetEnd)
+		}
+	}
+	for i := range o.Relations {
+		r := &o.Relations[i]
+		r.OffsetStart, r.OffsetEnd = start(r.OffsetStart), end(r.OffsetEnd)
+	}
+	if o.Hierarchy != nil {
+		for i := range o.Hierarchy.Taxa {
+			h := &o.Hierarchy.Taxa[i]
+			h.OffsetStart, h.OffsetEnd = start(h.OffsetStart), end(h.OffsetEnd)
+		}
+	}
+	for i := range o.MaskedRanges {
+		m := &o.MaskedRanges[i]
+		m.OffsetStart, m.OffsetEnd = start(m.OffsetStart), end(m.OffsetEnd)
 	}
-	rtb[len(text)] = bytes
 }
 
+// getTokensAround saves words around a name that starts with the token
+// index. The end is the index of the first token after the name, for
+// hybrid formulas it is the token after the last parent.
 func getTokensAround(
 	ts []token.TokenSN,
-	index int,
+	index, end int,
 	name *Name,
 	tokensAround int,
 ) {
-	limit := 5
-	tooBig := 30
-	before := index - tokensAround
-	after := make([]token.TokenSN, 0, limit)
-	if before < 0 {
-		before = 0
-	}
+	before := max(index-tokensAround, 0)
 	name.WordsBefore = make([]string, 0, index-before)
 	for _, t := range ts[before:index] {
-		if len(t.Cleaned()) < tooBig {
-			name.WordsBefore = append(name.WordsBefore, string(t.Raw()))
-		}
+		name.WordsBefore = append(name.WordsBefore, string(t.Raw()))
 	}
 	name.WordsAfter = make([]string, 0, tokensAround)
-	count := 0
-	for _, t := range ts[index:] {
-		if count == limit {
+	for _, t := range ts[min(end, len(ts)):] {
+		if len(name.WordsAfter) == tokensAround {
 			break
 		}
 		if name.OffsetEnd > t.Start() {
 			continue
 		}
-		if count < tokensAround && len(t.Cleaned()) < 30 {
-			name.WordsAfter = append(name.WordsAfter, string(t.Raw()))
-		}
-		after = append(after, t)
-		count++
+		name.WordsAfter = append(name.WordsAfter, string(t.Raw()))
 	}
-	name.AnnotNomen = annotNomen(after)
-	name.AnnotNomenType = normalizeAnnotNomen(name.AnnotNomen)
-}
-
-func normalizeAnnotNomen(annot string) string {
-	if len(annot) == 0 {
-		return "NO_ANNOT"
-	}
-
-	if strings.Contains(annot, "subsp") || strings.Contains(annot, "ssp") {
-		return "SUBSP_NOV"
-	}
-
-	if strings.Contains(annot, "sp") {
-		return "SP_NOV"
-	}
-
-	if strings.Contains(annot, "comb") {
-		return "COMB_NOV"
-	}
-
-	if strings.Contains(annot, "nom") {
-		return "NOM_NOV"
-	}
-
-	return "NO_ANNOT"
-}
-
-func annotNomen(after []token.TokenSN) string {
-	annot := make([]string, 0, 2)
-	nNum := 0
-	for _, v := range after {
-		if len(annot) > 1 {
-			break
-		}
-		annotNoSpace, ok := noSpaceAnnot(v)
-		if ok {
-			return annotNoSpace
-		}
-
-		c := v.Cleaned()
-		isN := (c == "n" || c == "nv" || c == "nov")
-		if isN {
-			nNum++
-		}
-		isSp := c == "sp" || c == "comb" || c == "subsp" ||
-			c == "ssp" || c == "nom"
-
-		if isN || isSp {
-			annot = append(annot, string(v.Raw()))
-		} else {
-			annot = annot[0:0]
-			nNum = 0
-		}
-	}
-	if len(annot) == 2 && nNum == 1 {
-		return strings.Join(annot, " ")
-	}
-	return ""
-}
-
-func noSpaceAnnot(t token.TokenSN) (string, bool) {
-	raw := string(t.Raw())
-	annots := []string{
-		"sp�nov", "comb�nov", "nom�nov",
-		"subsp�nov", "ssp�nov",
-	}
-	for i := range annots {
-		if t.Cleaned() == annots[i] {
-			return strings.TrimSpace(raw), true
-		}
-	}
-	return "", false
 }
 
 // UniqueNameStrings takes a list of names, and returns a list of unique
@@ -194,7 +170,7 @@ func (o *Output) UniqueNameStrings() []string {
 	var uniqueNames []string
 
 	for _, n := range o.Names {
-		set[n.Name] = struct{}{}
+		set[n.verifName()] = struct{}{}
 	}
 
 	for n := range set {
@@ -204,6 +180,15 @@ func (o *Output) UniqueNameStrings() []string {
 	return uniqueNames
 }
 
+// verifName returns the name-string that is sent to verification. If
+// abbreviated genus of a name was expanded, the expanded form is used.
+func (n Name) verifName() string {
+	if n.ExpandedName != "" {
+		return n.ExpandedName
+	}
+	return n.Name
+}
+
 // MergeVerification takes a map with verified names and
 // incorporates into output.
 func (o *Output) MergeVerification(
@@ -212,7 +197,7 @@ func (o *Output) MergeVerification(
 	dur float32,
 ) {
 	for i := range o.Names {
-		if nameRec, ok := v[o.Names[i].Name]; ok {
+		if nameRec, ok := v[o.Names[i].verifName()]; ok {
 			o.Names[i].Verification = &nameRec
 		}
 	}
@@ -243,24 +228,81 @@ func (o *Output) getStats(st stats.Stats) {
 	o.StatsNamesNum = st.NamesNum
 }
 
-func tokensToName(ts []token.TokenSN, text []rune, cfg config.Config) Name {
+func tokensToName(ts []token.TokenSN, text []rune) Name {
+	var name Name
 	u := ts[0]
 	switch u.Decision().Cardinality() {
 	case 1:
-		return uninomialName(u, text, cfg)
+		name = uninomialName(u, text)
 	case 2:
-		return speciesName(u, ts[u.Indices().Species], text, cfg)
+		name = speciesName(u, ts[u.Indices().Species], text)
 	case 3:
-		return infraspeciesName(ts, text, cfg)
+		name = infraspeciesName(ts, text)
 	default:
 		panic(fmt.Errorf("unkown Decision: %s", u.Decision()))
 	}
+	if i := u.Indices().Qualifier; i > 0 {
+		name.Qualifier = ts[i].Features().Qualifier.String()
+	}
+	name.InTable = u.Features().InTable
+	words := nameTokens(ts)
+	name.Corrected = isCorrected(words)
+	name.SuggestedName = suggestedName(name.Name, words)
+	return name
+}
+
+// nameTokens returns tokens that correspond to words of a name.
+func nameTokens(ts []token.TokenSN) []token.TokenSN {
+	u := ts[0]
+	res := []token.TokenSN{u}
+	switch u.Decision().Cardinality() {
+	case 2:
+		res = append(res, ts[u.Indices().Species])
+	case 3:
+		res = append(res, ts[u.Indices().Species])
+		if i := u.Indices().Rank; i > 0 {
+			res = append(res, ts[i])
+		}
+		res = append(res, ts[u.Indices().Infraspecies])
+	}
+	return res
+}
+
+// isCorrected checks if any word of a name was changed by historic text
+// normalization.
+func isCorrected(words []token.TokenSN) bool {
+	for _, w := range words {
+		if w.Features().Corrected {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestedName substitutes words of a name that were matched to the
+// dictionaries by fuzzy lookups. It returns an empty string if there
+// were no fuzzy matches.
+func suggestedName(name string, words []token.TokenSN) string {
+	var fuzzy bool
+	parts := strings.Split(name, " ")
+	if len(parts) != len(words) {
+		return ""
+	}
+	for i, w := range words {
+		if m := w.Features().FuzzyMatch; m != "" {
+			parts[i] = m
+			fuzzy = true
+		}
+	}
+	if !fuzzy {
+		return ""
+	}
+	return strings.Join(parts, " ")
 }
 
 func uninomialName(
 	u token.TokenSN,
 	text []rune,
-	cfg config.Config,
 ) Name {
 	name := Name{
 		Cardinality: u.Decision().Cardinality(),
@@ -269,28 +311,20 @@ func uninomialName(
 		Name:        u.Cleaned(),
 		OffsetStart: u.Start(),
 		OffsetEnd:   u.End(),
+		Rank:        u.Features().RankSuffix,
 	}
 	if len(u.NLP().OddsDetails) == 0 {
 		return name
 	}
 
 	name.OddsDetails = u.NLP().OddsDetails
-	if cfg.WithPositionInBytes {
-		offsetsToBytes(&name)
-	}
 	return name
 }
 
-func offsetsToBytes(name *Name) {
-	name.OffsetStart = rtb[name.OffsetStart]
-	name.OffsetEnd = rtb[name.OffsetEnd]
-}
-
 func speciesName(
 	g token.TokenSN,
 	s token.TokenSN,
 	text []rune,
-	cfg config.Config,
 ) Name {
 	name := Name{
 		Cardinality: g.Decision().Cardinality(),
@@ -309,17 +343,12 @@ func speciesName(
 	for k, v := range s.NLP().OddsDetails {
 		name.OddsDetails[k] = v
 	}
-
-	if cfg.WithPositionInBytes {
-		offsetsToBytes(&name)
-	}
 	return name
 }
 
 func infraspeciesName(
 	ts []token.TokenSN,
 	text []rune,
-	cfg config.Config,
 ) Name {
 	g := ts[0]
 	sp := ts[g.Indices().Species]
@@ -350,10 +379,6 @@ func infraspeciesName(
 	for k, v := range isp.NLP().OddsDetails {
 		name.OddsDetails[k] = v
 	}
-
-	if cfg.WithPositionInBytes {
-		offsetsToBytes(&name)
-	}
 	return name
 }
 
@@ -366,7 +391,11 @@ func infraspeciesString(
 	if g.Indices().Rank == 0 {
 		return fmt.Sprintf("%s %s %s", g.Cleaned(), sp.Cleaned(), isp.Cleaned())
 	}
-	return fmt.Sprintf("%s %s %s %s", g.Cleaned(), sp.Cleaned(), string(rank.Raw()),
+	rankStr := string(rank.Raw())
+	if rank.Features().AllCaps {
+		rankStr = strings.ToLower(rankStr)
+	}
+	return fmt.Sprintf("%s %s %s %s", g.Cleaned(), sp.Cleaned(), rankStr,
 		isp.Cleaned())
 }
 
@@ -0,0 +1,207 @@
+package output
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gnames/gnfinder/pkg/ent/token"
+)
+
+// Relation links a found name to another found name, or describes how
+// the name is used in the text, for example "Aus bus = Cus dus",
+// "Aus bus sensu lato", "Aus bus non Smith, 1900".
+type Relation struct {
+	// Type is a normalized type of the relation. Its values are given by
+	// the RelationType type.
+	Type string `json:"type"`
+
+	// NameIndex is the index of the name in the Names list.
+	NameIndex int `json:"nameIndex"`
+
+	// RelatedIndex is the index of the related name in the Names list.
+	// It is -1 if the statement concerns only one name, for example
+	// "Aus bus sensu lato".
+	RelatedIndex int `json:"relatedIndex"`
+
+	// Verbatim is the relation statement as it appears in the text.
+	Verbatim string `json:"verbatim"`
+
+	// OffsetStart is the start of the statement in the text.
+	OffsetStart int `json:"start"`
+
+	// OffsetEnd is the end of the statement in the text.
+	OffsetEnd int `json:"end"`
+}
+
+// RelationType is a normalized type of a relation between names.
+type RelationType int
+
+// Possible RelationTypes
+const (
+	NoRelation RelationType = iota
+	Synonym
+	Misapplied
+	Sensu
+	Non
+)
+
+var relationTypesStrings = [...]string{"NO_RELATION", "SYNONYM",
+	"MISAPPLIED", "SENSU", "NON",
+}
+
+// String representation of a RelationType.
+func (r RelationType) String() string {
+	return relationTypesStrings[r]
+}
+
+// relationWords contain words that mark relations between names.
+var relationWords = map[string]RelationType{
+	"syn": Synonym, "synonym": Synonym, "synonyms": Synonym,
+	"synonymy": Synonym,
+	"auct":     Misapplied, "auctt": Misapplied, "auctorum": Misapplied,
+	"misapplied": Misapplied, "misidentified": Misapplied,
+	"misidentification": Misapplied,
+	"sensu":             Sensu, "s�l": Sensu, "s�lat": Sensu, "s�str": Sensu,
+	"s�s": Sensu,
+	"non": Non, "nec": Non,
+}
+
+// relCut are characters that are removed from the edges of a relation
+// statement.
+const relCut = "()[],;: \t\r\n"
+
+// nameRelations finds relation statements in tokens between adjacent
+// names. A relation links two names if the next name follows the
+// statement closely.
+func nameRelations(ts []token.TokenSN, names []Name) []Relation {
+	gap := 8
+	var res []Relation
+	var k int
+	for i := range names {
+		k = tokenIndex(ts, k, max(names[i].OffsetEnd, names[i].AnnotNomenEnd))
+		limit := min(len(ts), k+gap)
+		next := -1
+		if i+1 < len(names) {
+			start := names[i+1].OffsetStart
+			if a := names[i+1]; a.AnnotNomen != "" && a.AnnotNomenStart < start {
+				start = a.AnnotNomenStart
+			}
+			j := tokenIndex(ts, k, start)
+			if j-k <= gap {
+				next = i + 1
+			}
+			limit = min(limit, j)
+		}
+		res = append(res, gapRelations(ts[k:limit], i, next)...)
+	}
+	return res
+}
+
+// gapRelations finds relations in tokens that follow the name with the
+// index i. The next is the index of the following name, or -1 if that
+// name is too far.
+func gapRelations(ts []token.TokenSN, i, next int) []Relation {
+	var res []Relation
+	for k := 0; k < len(ts); k++ {
+		rt := relationMarker(ts, k)
+		if rt == NoRelation {
+			continue
+		}
+		if next >= 0 && (rt == Synonym || k+1 == len(ts)) {
+			res = append(res, newRelation(ts[k:k+1], rt, i, next))
+			continue
+		}
+		if rt == Synonym {
+			continue
+		}
+		end := relationContext(ts, k)
+		res = append(res, newRelation(ts[k:end], rt, i, -1))
+		k = end - 1
+	}
+	return res
+}
+
+// relationMarker returns the type of a relation, if the token k marks it.
+func relationMarker(ts []token.TokenSN, k int) RelationType {
+	raw := strings.Trim(string(ts[k].Raw()), relCut)
+	if raw == "=" {
+		return Synonym
+	}
+	w := strings.ToLower(ts[k].Cleaned())
+	if rt, ok := relationWords[w]; ok {
+		return rt
+	}
+	if w == "s" && strings.HasSuffix(raw, ".") && k+1 < len(ts) {
+		if _, ok := sensuWords[strings.ToLower(ts[k+1].Cleaned())]; ok {
+			return Sensu
+		}
+	}
+	return NoRelation
+}
+
+// relationContext returns the index of the token after a statement that
+// starts with the marker k, for example "sensu lato" or
+// "non Smith, 1900". The context can contain authors, years and
+// words that qualify "sensu".
+func relationContext(ts []token.TokenSN, k int) int {
+	limit := 3
+	end := k + 1
+	for end < len(ts) && end <= k+limit {
+		if relationMarker(ts, end) != NoRelation || !isRelationContext(ts[end]) {
+			break
+		}
+		raw := ts[end].Raw()
+		end++
+		last := raw[len(raw)-1]
+		if last == ',' && end < len(ts) && unicode.IsDigit(ts[end].Raw()[0]) {
+			continue
+		}
+		if strings.ContainsRune(",;:)", last) ||
+			(last == '.' && unicode.IsDigit(raw[0])) {
+			break
+		}
+	}
+	return end
+}
+
+// sensuWords qualify "sensu" statements.
+var sensuWords = map[string]struct{}{
+	"lato": {}, "latu": {}, "stricto": {}, "strictu": {}, "amplo": {},
+	"l": {}, "lat": {}, "s": {}, "str": {}, "ampl": {},
+}
+
+// isRelationContext checks if a token can be a part of a relation
+// statement after its marker.
+func isRelationContext(t token.TokenSN) bool {
+	raw := []rune(strings.Trim(string(t.Raw()), relCut))
+	if len(raw) == 0 {
+		return false
+	}
+	if unicode.IsDigit(raw[0]) || raw[0] == '&' {
+		return true
+	}
+	w := strings.ToLower(t.Cleaned())
+	if _, ok := sensuWords[w]; ok {
+		return true
+	}
+	return w == "et" || unicode.IsUpper(raw[0])
+}
+
+func newRelation(
+	ts []token.TokenSN,
+	rt RelationType,
+	i, related int,
+) Relation {
+	raws := make([]string, len(ts))
+	for k, t := range ts {
+		raws[k] = strings.TrimSpace(string(t.Raw()))
+	}
+	return Relation{
+		Type:         rt.String(),
+		NameIndex:    i,
+		RelatedIndex: related,
+		Verbatim:     strings.Trim(strings.Join(raws, " "), relCut),
+		OffsetStart:  trimmedStart(ts[0], relCut),
+		OffsetEnd:    trimmedEnd(ts[len(ts)-1], relCut),
+	}
+}
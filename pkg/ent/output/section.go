@@ -0,0 +1,61 @@
+package output
+
+import (
+	"slices"
+
+	"github.com/gnames/gnfinder/pkg/ent/section"
+)
+
+// AddSections sets sections of names and removes names found in excluded
+// sections. Offsets of names have to refer to the text of the spans.
+func (o *Output) AddSections(spans []section.Span, exclude []section.Section) {
+	keep := make([]bool, len(o.Names))
+	for i := range o.Names {
+		n := &o.Names[i]
+		s := section.Of(spans, n.OffsetStart)
+		n.Section = s.String()
+		keep[i] = !slices.Contains(exclude, s)
+	}
+	o.keepNames(keep)
+}
+
+// keepNames removes names that are not kept, and relations of the removed
+// names.
+func (o *Output) keepNames(keep []bool) {
+	if !slices.Contains(keep, false) {
+		return
+	}
+	idx := make([]int, len(o.Names))
+	names := make([]Name, 0, len(o.Names))
+	for i, v := range o.Names {
+		idx[i] = -1
+		if keep[i] {
+			idx[i] = len(names)
+			names = append(names, v)
+		}
+	}
+	var rels []Relation
+	for _, v := range o.Relations {
+		if idx[v.NameIndex] < 0 ||
+			(v.RelatedIndex >= 0 && idx[v.RelatedIndex] < 0) {
+			continue
+		}
+		v.NameIndex = idx[v.NameIndex]
+		if v.RelatedIndex >= 0 {
+			v.RelatedIndex = idx[v.RelatedIndex]
+		}
+		rels = append(rels, v)
+	}
+	o.Names, o.Relations = names, rels
+}
+
+func sectionStrings(ss []section.Section) []string {
+	if len(ss) == 0 {
+		return nil
+	}
+	res := make([]string, len(ss))
+	for i, v := range ss {
+		res[i] = v.String()
+	}
+	return res
+}
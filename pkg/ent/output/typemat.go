@@ -0,0 +1,257 @@
+package output
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gnames/gnfinder/pkg/ent/token"
+)
+
+// TypeMaterial is a mention of a type designation, examined material or
+// a specimen code found in the text after a newly described name.
+type TypeMaterial struct {
+	// Type is a normalized kind of the mention. Its values are given by
+	// the MaterialType type.
+	Type string `json:"type"`
+
+	// Verbatim is the mention as it appears in the text.
+	Verbatim string `json:"verbatim"`
+
+	// Designation is the type designation of a specimen, for example
+	// "HOLOTYPE", if the specimen code follows a type designation keyword.
+	Designation string `json:"designation,omitempty"`
+
+	// Collection is a collection code of a specimen, for example "MCZ".
+	Collection string `json:"collection,omitempty"`
+
+	// CatalogNumber is a catalog number of a specimen, for example "12345".
+	CatalogNumber string `json:"catalogNumber,omitempty"`
+
+	// OffsetStart is the start of the mention in the text.
+	OffsetStart int `json:"start"`
+
+	// OffsetEnd is the end of the mention in the text.
+	OffsetEnd int `json:"end"`
+}
+
+// MaterialType is a normalized kind of a type material mention.
+type MaterialType int
+
+// Possible MaterialTypes
+const (
+	NoMaterial MaterialType = iota
+	Holotype
+	Paratype
+	Allotype
+	Lectotype
+	Paralectotype
+	Neotype
+	Syntype
+	Topotype
+	TypeMaterialKw
+	MaterialExamined
+	Specimen
+)
+
+var materialTypesStrings = [...]string{"NO_MATERIAL", "HOLOTYPE",
+	"PARATYPE", "ALLOTYPE", "LECTOTYPE", "PARALECTOTYPE", "NEOTYPE",
+	"SYNTYPE", "TOPOTYPE", "TYPE_MATERIAL", "MATERIAL_EXAMINED", "SPECIMEN",
+}
+
+// String representation of a MaterialType.
+func (m MaterialType) String() string {
+	return materialTypesStrings[m]
+}
+
+// typeDesignations contain Latin and English forms of type designations.
+var typeDesignations = map[string]MaterialType{
+	"holotype": Holotype, "holotypes": Holotype, "holotypus": Holotype,
+	"paratype": Paratype, "paratypes": Paratype, "paratypus": Paratype,
+	"paratypi": Paratype,
+	"allotype": Allotype, "allotypes": Allotype, "allotypus": Allotype,
+	"lectotype": Lectotype, "lectotypes": Lectotype, "lectotypus": Lectotype,
+	"paralectotype": Paralectotype, "paralectotypes": Paralectotype,
+	"paralectotypi": Paralectotype,
+	"neotype":       Neotype, "neotypes": Neotype, "neotypus": Neotype,
+	"syntype": Syntype, "syntypes": Syntype, "syntypus": Syntype,
+	"syntypi":  Syntype,
+	"topotype": Topotype, "topotypes": Topotype,
+}
+
+// materialPhrases contain two-word phrases that introduce lists of
+// specimens.
+var materialPhrases = map[[2]string]MaterialType{
+	{"type", "material"}:      TypeMaterialKw,
+	{"material", "examined"}:  MaterialExamined,
+	{"examined", "material"}:  MaterialExamined,
+	{"material", "studied"}:   MaterialExamined,
+	{"studied", "material"}:   MaterialExamined,
+	{"specimens", "examined"}: MaterialExamined,
+}
+
+var (
+	collectionRe = regexp.MustCompile(`^[A-Z]{2,8}$`)
+	romanRe      = regexp.MustCompile(`^[IVXLCDM]+$`)
+	catalogRe    = regexp.MustCompile(`^\d+(?:[.\-/]\d+)*$`)
+	specimenRe   = regexp.MustCompile(
+		`^([A-Z]{2,8}(?:[:\-][A-Za-z]+)*)[:\-](\d+(?:[.\-/]\d+)*)$`,
+	)
+)
+
+// typeMaterials finds type designations, examined material and specimen
+// codes in tokens that follow names with nomenclatural annotations.
+// The search for a name stops at the start of the next name. Possible
+// uninomials are not used as the stop, because words like "Holotype" or
+// "Material" are often detected as such.
+func typeMaterials(ts []token.TokenSN, names []Name) {
+	var k int
+	for i := range names {
+		name := &names[i]
+		if name.AnnotNomenType == "" || name.AnnotNomenType == NoAnnot.String() {
+			continue
+		}
+		end := max(name.OffsetEnd, name.AnnotNomenEnd)
+		for k < len(ts) && ts[k].Start() < end {
+			k++
+		}
+		limit := len(ts)
+		for _, next := range names[i+1:] {
+			if next.Decision != token.PossibleUninomial {
+				limit = tokenIndex(ts, k, next.OffsetStart)
+				break
+			}
+		}
+		typeMaterial(ts[k:limit], name)
+	}
+}
+
+// tokenIndex returns the index of the first token starting at or after
+// the offset. The search starts from the token i.
+func tokenIndex(ts []token.TokenSN, i, offset int) int {
+	for i < len(ts) && ts[i].Start() < offset {
+		i++
+	}
+	return i
+}
+
+// typeMaterial scans tokens for type material of a name.
+func typeMaterial(ts []token.TokenSN, name *Name) {
+	limit := 40
+	designation := NoMaterial
+	for k := 0; k < len(ts) && k < limit; k++ {
+		if k+1 < len(ts) {
+			if m := phraseMaterial(ts[k], ts[k+1]); m != NoMaterial {
+				addMaterial(ts, k, k+2, m, name)
+				designation = NoMaterial
+				k++
+				continue
+			}
+		}
+
+		word := strings.ToLower(ts[k].Cleaned())
+		if m, ok := typeDesignations[word]; ok {
+			addMaterial(ts, k, k+1, m, name)
+			designation = m
+			continue
+		}
+
+		raw := trimPunct(ts[k].Raw())
+		if res := specimenRe.FindStringSubmatch(raw); res != nil {
+			addSpecimen(ts, k, k+1, res[1], res[2], designation, name)
+			continue
+		}
+
+		if k+1 < len(ts) && collectionRe.MatchString(raw) &&
+			!romanRe.MatchString(raw) {
+			num := trimPunct(ts[k+1].Raw())
+			if catalogRe.MatchString(num) {
+				addSpecimen(ts, k, k+2, raw, num, designation, name)
+				k++
+			}
+		}
+	}
+}
+
+// phraseMaterial detects two-word phrases like "Material examined".
+func phraseMaterial(t1, t2 token.TokenSN) MaterialType {
+	w1 := strings.ToLower(t1.Cleaned())
+	w2 := strings.ToLower(t2.Cleaned())
+	return materialPhrases[[2]string{w1, w2}]
+}
+
+// addMaterial saves a keyword mention created from tokens with indices from
+// start to end (exclusive) to the name.
+func addMaterial(
+	ts []token.TokenSN,
+	start, end int,
+	m MaterialType,
+	name *Name,
+) {
+	tm := TypeMaterial{
+		Type:        m.String(),
+		Verbatim:    verbatimTokens(ts[start:end]),
+		OffsetStart: trimmedStart(ts[start], punct),
+		OffsetEnd:   trimmedEnd(ts[end-1], punct),
+	}
+	name.TypeMaterial = append(name.TypeMaterial, tm)
+}
+
+// addSpecimen saves a specimen code created from tokens with indices from
+// start to end (exclusive) to the name.
+func addSpecimen(
+	ts []token.TokenSN,
+	start, end int,
+	coll, num string,
+	designation MaterialType,
+	name *Name,
+) {
+	tm := TypeMaterial{
+		Type:          Specimen.String(),
+		Verbatim:      verbatimTokens(ts[start:end]),
+		Collection:    coll,
+		CatalogNumber: num,
+		OffsetStart:   trimmedStart(ts[start], punct),
+		OffsetEnd:     trimmedEnd(ts[end-1], punct),
+	}
+	if designation != NoMaterial {
+		tm.Designation = designation.String()
+	}
+	name.TypeMaterial = append(name.TypeMaterial, tm)
+}
+
+func verbatimTokens(ts []token.TokenSN) string {
+	raws := make([]string, len(ts))
+	for i, t := range ts {
+		raws[i] = trimPunct(t.Raw())
+	}
+	return strings.Join(raws, " ")
+}
+
+const punct = "()[],;:. \t\r\n"
+
+// trimPunct removes brackets and punctuation around a word.
+func trimPunct(raw []rune) string {
+	return strings.Trim(string(raw), punct)
+}
+
+// trimmedStart returns the start of a token without leading characters
+// from the cutset.
+func trimmedStart(t token.TokenSN, cutset string) int {
+	raw := t.Raw()
+	var i int
+	for i < len(raw) && strings.ContainsRune(cutset, raw[i]) {
+		i++
+	}
+	return t.Start() + i
+}
+
+// trimmedEnd returns the end of a token without trailing characters
+// from the cutset.
+func trimmedEnd(t token.TokenSN, cutset string) int {
+	raw := t.Raw()
+	i := len(raw)
+	for i > 0 && strings.ContainsRune(cutset, raw[i-1]) {
+		i--
+	}
+	return t.End() - (len(raw) - i)
+}
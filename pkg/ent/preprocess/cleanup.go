@@ -0,0 +1,288 @@
+package preprocess
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// pageNumberRe matches lines that contain only a page number, for example
+// "12", "- 12 -", "p. 12", "Seite 12" or "xii".
+var pageNumberRe = regexp.MustCompile(
+	`^(?i:(?:page|p\.|s\.|seite)\s*)?[-–—]?\s*(?:\d{1,4}|(?i:[ivxlc]{1,6}))\s*[-–—]?$`,
+)
+
+// linesNum is the number of non-empty lines at the top and at the bottom
+// of a page that are checked for running headers, footers and page
+// numbers.
+const linesNum = 2
+
+// Mask is a part of a text removed by Cleanup as an artifact of page
+// layout.
+type Mask struct {
+	// Kind of the artifact: "header", "footer", "pageNumber" or
+	// "pageMarker".
+	Kind string
+
+	// Start of the artifact in the text.
+	Start int
+
+	// End of the artifact in the text, including its new line character.
+	End int
+}
+
+// Cleanup removes artifacts of page layout from a text with pages
+// separated by form feeds or by lines that match the marker, if it is not
+// nil. It drops page markers, page numbers, running headers and footers
+// repeated on several pages, and hyphens at the ends of lines, if the word
+// joined without the hyphen is known to the `known` function. If the word
+// is known in its hyphenated form, only the line break is removed.
+// Cleanup keeps a map of positions back to the original text, and the
+// removed page artifacts.
+func Cleanup(
+	text []rune,
+	marker *regexp.Regexp,
+	known func(string) bool,
+) Text {
+	masked := pageArtifacts(text, marker)
+	drops := make([][2]int, len(masked))
+	for i, m := range masked {
+		drops[i] = [2]int{m.Start, m.End}
+	}
+	drops = append(drops, hyphenations(text, drops, known)...)
+	if len(drops) == 0 {
+		return Text{Text: text}
+	}
+	slices.SortFunc(drops, func(a, b [2]int) int { return a[0] - b[0] })
+
+	res := newText(len(text))
+	var d int
+	for i := 0; i < len(text); i++ {
+		for d < len(drops) && drops[d][1] <= i {
+			d++
+		}
+		if d < len(drops) && drops[d][0] <= i {
+			i = drops[d][1] - 1
+			continue
+		}
+		res.add(text[i], i, i+1)
+	}
+	res.Masked = masked
+	return *res
+}
+
+// line is a line of a text without its new line character.
+type line struct {
+	start, end int
+}
+
+// pageArtifacts finds page markers, and page numbers, running headers and
+// footers at the top and at the bottom of pages. It returns their lines
+// together with new line characters sorted by their start.
+func pageArtifacts(text []rune, marker *regexp.Regexp) []Mask {
+	pages, markers := pageLines(text, marker)
+
+	var res []Mask
+	mask := func(kind string, l line) {
+		end := l.end
+		if end < len(text) && text[end] == '\n' {
+			end++
+		}
+		res = append(res, Mask{Kind: kind, Start: l.start, End: end})
+	}
+	for _, l := range markers {
+		mask("pageMarker", l)
+	}
+	if len(pages) < 2 {
+		return res
+	}
+
+	tops := make(map[string]int)
+	bottoms := make(map[string]int)
+	for _, p := range pages {
+		for _, key := range lineKeys(text, topLines(p)) {
+			tops[key]++
+		}
+		for _, key := range lineKeys(text, bottomLines(p)) {
+			bottoms[key]++
+		}
+	}
+
+	check := func(l line, kind string, counts map[string]int) {
+		s := string(text[l.start:l.end])
+		switch {
+		case pageNumberRe.MatchString(strings.TrimSpace(s)):
+			mask("pageNumber", l)
+		case isRepeated(counts[headerKey(s)], len(pages)):
+			mask(kind, l)
+		}
+	}
+	for _, p := range pages {
+		top, bottom := topLines(p), bottomLines(p)
+		for _, l := range top {
+			check(l, "header", tops)
+		}
+		for _, l := range bottom {
+			if !slices.Contains(top, l) {
+				check(l, "footer", bottoms)
+			}
+		}
+	}
+	slices.SortFunc(res, func(a, b Mask) int { return a.Start - b.Start })
+	return res
+}
+
+// isRepeated decides if a line is a running header or footer by the
+// number of pages where it was found.
+func isRepeated(count, pagesNum int) bool {
+	return count >= 2 && count*4 >= pagesNum
+}
+
+// pageLines splits a text into pages by form feeds and page markers, and
+// pages into non-empty lines. It also returns lines of page markers. Page
+// markers do not create empty pages, for example if the text starts with a
+// marker.
+func pageLines(text []rune, marker *regexp.Regexp) ([][]line, []line) {
+	var res [][]line
+	var markers []line
+	var page []line
+	start := 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && text[i] != '\n' && text[i] != '\f' {
+			continue
+		}
+		l := line{start: start, end: i}
+		if i > 0 && text[i-1] == '\r' && i-1 >= start {
+			l.end = i - 1
+		}
+		start = i + 1
+		s := strings.TrimSpace(string(text[l.start:l.end]))
+		switch {
+		case s == "":
+		case marker != nil && marker.MatchString(s):
+			markers = append(markers, l)
+			if len(page) > 0 {
+				res = append(res, page)
+				page = nil
+			}
+		default:
+			page = append(page, l)
+		}
+		if i == len(text) || text[i] == '\f' {
+			res = append(res, page)
+			page = nil
+		}
+	}
+	return res, markers
+}
+
+func topLines(page []line) []line {
+	return page[:min(linesNum, len(page))]
+}
+
+func bottomLines(page []line) []line {
+	return page[max(0, len(page)-linesNum):]
+}
+
+func lineKeys(text []rune, ls []line) []string {
+	res := make([]string, 0, len(ls))
+	for _, l := range ls {
+		if key := headerKey(string(text[l.start:l.end])); key != "" {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// headerKey normalizes a line for comparison with lines of other pages.
+// Running headers differ only by page numbers, so digits are ignored.
+// Lines with less than three letters have an empty key.
+func headerKey(s string) string {
+	var letters int
+	f := func(r rune) rune {
+		switch {
+		case unicode.IsDigit(r):
+			return -1
+		case unicode.IsLetter(r):
+			letters++
+			return unicode.ToLower(r)
+		}
+		return r
+	}
+	res := strings.Join(strings.Fields(strings.Map(f, s)), " ")
+	if letters < 3 {
+		return ""
+	}
+	return res
+}
+
+// hyphenations finds hyphens at the ends of lines that split known words.
+// It returns ranges of the hyphens and of the spaces that follow them.
+// Dropped ranges, for example running headers, are skipped on the way to
+// the continuation of a word.
+func hyphenations(
+	text []rune,
+	drops [][2]int,
+	known func(string) bool,
+) [][2]int {
+	var res [][2]int
+	dropEnds := make(map[int]int, len(drops))
+	for _, d := range drops {
+		dropEnds[d[0]] = d[1]
+	}
+	for i := 1; i < len(text)-1; i++ {
+		if !isHyphen(text[i]) || !unicode.IsLetter(text[i-1]) {
+			continue
+		}
+		j, ok := nextLineWord(text, i+1, dropEnds)
+		if !ok || !unicode.IsLower(text[j]) {
+			continue
+		}
+		start := i - 1
+		for start > 0 && unicode.IsLetter(text[start-1]) {
+			start--
+		}
+		end := j
+		for end < len(text) && unicode.IsLetter(text[end]) {
+			end++
+		}
+		left, right := string(text[start:i]), string(text[j:end])
+		switch {
+		case known(left + right):
+			res = append(res, [2]int{i, j})
+		case known(left + "-" + right):
+			res = append(res, [2]int{i + 1, j})
+		}
+	}
+	return res
+}
+
+// isHyphen checks for characters used for hyphenation at the ends of
+// lines. OCR of old texts often renders hyphens as "¬".
+func isHyphen(r rune) bool {
+	return r == '-' || r == '\u2010' || r == '¬'
+}
+
+// nextLineWord checks that only spaces follow position i till the end of
+// the line, and returns the position of the first character of the next
+// line that is not a space and is not dropped. Dropped ranges are given
+// by their starts and ends.
+func nextLineWord(text []rune, i int, dropEnds map[int]int) (int, bool) {
+	var newLine bool
+	for i < len(text) {
+		if end, ok := dropEnds[i]; ok {
+			i = end
+			continue
+		}
+		switch text[i] {
+		case '\n', '\f':
+			newLine = true
+		case ' ', '\t', '\r':
+		default:
+			return i, newLine
+		}
+		i++
+	}
+	return i, false
+}
@@ -0,0 +1,92 @@
+package preprocess_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/preprocess"
+	"github.com/stretchr/testify/assert"
+)
+
+func known(w string) bool {
+	switch w {
+	case "Pardosa", "moesta", "novae-angliae":
+		return true
+	}
+	return false
+}
+
+func TestCleanupHyphens(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, text, res string
+	}{
+		{"known genus", "The Pardo-\nsa moesta", "The Pardosa moesta"},
+		{"known epithet", "Pardosa moes- \n  ta Banks", "Pardosa moesta Banks"},
+		{"ocr hyphen", "The Pardo¬\nsa moesta", "The Pardosa moesta"},
+		{"hyphenated epithet", "Aster novae-\nangliae", "Aster novae-angliae"},
+		{"unknown word", "The spi-\nders", "The spi-\nders"},
+		{"not line end", "The Pardo-sa", "The Pardo-sa"},
+		{"capitalized", "The Pardo-\nSa", "The Pardo-\nSa"},
+	}
+	for _, v := range tests {
+		res := preprocess.Cleanup([]rune(v.text), nil, known)
+		assert.Equal(v.res, string(res.Text), v.msg)
+	}
+
+	res := preprocess.Cleanup([]rune("The Pardo-\nsa moesta"), nil, known)
+	assert.Equal(4, res.OriginStart(4))
+	assert.Equal(20, res.OriginEnd(18))
+	// "s" after the removed hyphen and new line
+	assert.Equal(11, res.OriginStart(9))
+}
+
+func TestCleanupPages(t *testing.T) {
+	assert := assert.New(t)
+	text := "JOURNAL OF ARACHNOLOGY 12\nSpiders of Ohio. The Pardo-\n" +
+		"- 12 -\n\f" +
+		"JOURNAL OF ARACHNOLOGY 13\nsa moesta is common.\n" +
+		"- 13 -\n\f" +
+		"JOURNAL OF ARACHNOLOGY 14\nNothing else.\n14"
+	res := preprocess.Cleanup([]rune(text), nil, known)
+	assert.Equal("Spiders of Ohio. The Pardosa moesta is common.\n"+
+		"\fNothing else.\n", string(res.Text))
+	kinds := make([]string, len(res.Masked))
+	for i, v := range res.Masked {
+		kinds[i] = v.Kind
+	}
+	assert.Equal([]string{
+		"header", "pageNumber", "header", "pageNumber", "header", "pageNumber",
+	}, kinds)
+	m := res.Masked[2]
+	assert.Equal("JOURNAL OF ARACHNOLOGY 13\n", string([]rune(text)[m.Start:m.End]))
+
+	// a single page does not have running headers
+	text = "JOURNAL OF ARACHNOLOGY\nPardosa moesta"
+	res = preprocess.Cleanup([]rune(text), nil, known)
+	assert.Equal(text, string(res.Text))
+}
+
+func TestCleanupPageMarker(t *testing.T) {
+	assert := assert.New(t)
+	marker := regexp.MustCompile(`^=+ Page \d+ =+$`)
+	text := "=== Page 1 ===\nBull. Ent. Soc. 3\nThe Pardo-\n" +
+		"=== Page 2 ===\nBull. Ent. Soc. 4\nsa moesta is common.\n" +
+		"=== Page 3 ===\nBull. Ent. Soc. 5\nNothing else."
+	res := preprocess.Cleanup([]rune(text), marker, known)
+	assert.Equal("The Pardosa moesta is common.\nNothing else.",
+		string(res.Text))
+	var markers int
+	for _, v := range res.Masked {
+		if v.Kind == "pageMarker" {
+			markers++
+		}
+	}
+	assert.Equal(3, markers)
+	assert.Equal(6, len(res.Masked))
+
+	// without the marker the text has only one page
+	res = preprocess.Cleanup([]rune(text), nil, known)
+	assert.Equal(text, string(res.Text))
+	assert.Nil(res.Masked)
+}
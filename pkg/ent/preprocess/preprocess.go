@@ -0,0 +1,250 @@
+// Package preprocess normalizes Unicode of a text before name-finding.
+// Texts come in a mix of composed and decomposed characters, with
+// invisible characters and exotic spaces inside of names. The package
+// removes such differences and keeps a map from positions in the
+// normalized text back to the original text.
+package preprocess
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Form is a Unicode normalization form applied to a text.
+type Form int
+
+// Supported normalization forms.
+const (
+	// NFC composes characters, for example "u" followed by combining
+	// diaeresis becomes "ü". It is the default form.
+	NFC Form = iota
+
+	// NFKC also substitutes compatibility characters, for example
+	// ligatures and full-width letters, by their usual forms.
+	NFKC
+
+	// NoForm turns normalization off.
+	NoForm
+)
+
+var formStrings = [...]string{"nfc", "nfkc", "none"}
+
+// String representation of a Form.
+func (f Form) String() string {
+	return formStrings[f]
+}
+
+// New takes a string and returns a matching normalization form. Empty
+// string returns NFC. If the string is unknown, it returns NFC and an
+// error.
+func New(s string) (Form, error) {
+	switch strings.ToLower(s) {
+	case "", "nfc":
+		return NFC, nil
+	case "nfkc":
+		return NFKC, nil
+	case "none", "no":
+		return NoForm, nil
+	}
+	return NFC, fmt.Errorf("unknown normalization form %s", s)
+}
+
+// FormStrings returns string representations of supported forms.
+func FormStrings() []string {
+	return slices.Clone(formStrings[:])
+}
+
+const softHyphen = '\u00ad'
+
+// Text is a normalized text.
+type Text struct {
+	// Text is the normalized text.
+	Text []rune
+
+	// Starts map positions of runes in Text to positions of runes in the
+	// original text. If Starts are empty, Text is the original text.
+	Starts []int
+
+	// Ends map positions of runes in Text to positions in the original
+	// text right after the runes.
+	Ends []int
+
+	// Masked are parts of the original text removed as artifacts of page
+	// layout, for example running headers.
+	Masked []Mask
+}
+
+// OriginStart returns a position in the original text that corresponds to
+// the start position i in the normalized text.
+func (t Text) OriginStart(i int) int {
+	if len(t.Starts) == 0 {
+		return i
+	}
+	if i < 0 || i >= len(t.Starts) {
+		return t.originLen(i)
+	}
+	return t.Starts[i]
+}
+
+// OriginEnd returns a position in the original text that corresponds to
+// the end position i in the normalized text.
+func (t Text) OriginEnd(i int) int {
+	if len(t.Ends) == 0 {
+		return i
+	}
+	if i <= 0 || i > len(t.Ends) {
+		return t.originLen(i)
+	}
+	return t.Ends[i-1]
+}
+
+func (t Text) originLen(i int) int {
+	if i <= 0 {
+		return 0
+	}
+	return t.Ends[len(t.Ends)-1]
+}
+
+// Position returns a position in the normalized text that corresponds to
+// the position i in the original text.
+func (t Text) Position(i int) int {
+	if len(t.Starts) == 0 {
+		return i
+	}
+	return sort.SearchInts(t.Starts, i)
+}
+
+// Spans converts start and end positions of fragments of the original
+// text, for example italics, to positions in the normalized text.
+func (t Text) Spans(spans [][2]int) [][2]int {
+	if len(t.Starts) == 0 || len(spans) == 0 {
+		return spans
+	}
+	res := make([][2]int, 0, len(spans))
+	for _, v := range spans {
+		s := [2]int{t.Position(v[0]), t.Position(v[1])}
+		if s[1] > s[0] {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Normalize applies a normalization form to a text. It also removes
+// zero-width characters and soft hyphens, and substitutes all kinds of
+// spaces by the usual space. A soft hyphen at the end of a line becomes
+// a hyphen, so the word can be joined with its continuation on the next
+// line. Texts that contain only ASCII characters are not changed.
+func Normalize(text []rune, f Form) Text {
+	if f == NoForm || isASCII(text) {
+		return Text{Text: text}
+	}
+	nf := norm.NFC
+	if f == NFKC {
+		nf = norm.NFKC
+	}
+
+	res := newText(len(text))
+	add := res.add
+	for i := 0; i < len(text); {
+		r := text[i]
+		switch {
+		case isInvisible(r):
+			i++
+			continue
+		case r == softHyphen:
+			if isLineEnd(text, i+1) {
+				add('-', i, i+1)
+			}
+			i++
+			continue
+		case r != ' ' && unicode.Is(unicode.Zs, r):
+			add(' ', i, i+1)
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(text) && !boundaryBefore(nf, text[j]) {
+			j++
+		}
+		seg := string(text[i:j])
+		nseg := nf.String(seg)
+		if nseg == seg {
+			for k := i; k < j; k++ {
+				add(text[k], k, k+1)
+			}
+		} else {
+			for _, nr := range nseg {
+				add(nr, i, j)
+			}
+		}
+		i = j
+	}
+	return *res
+}
+
+func newText(l int) *Text {
+	return &Text{
+		Text:   make([]rune, 0, l),
+		Starts: make([]int, 0, l),
+		Ends:   make([]int, 0, l),
+	}
+}
+
+// add appends a rune of the normalized text that originates from the
+// original text between start and end.
+func (t *Text) add(r rune, start, end int) {
+	t.Text = append(t.Text, r)
+	t.Starts = append(t.Starts, start)
+	t.Ends = append(t.Ends, end)
+}
+
+func isASCII(text []rune) bool {
+	for _, r := range text {
+		if r >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// isInvisible checks for zero-width characters that can break a word
+// into parts, and for byte order marks inside of a text.
+func isInvisible(r rune) bool {
+	switch r {
+	case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+		return true
+	}
+	return false
+}
+
+// isLineEnd checks if only spaces separate position i from a new line.
+func isLineEnd(text []rune, i int) bool {
+	for ; i < len(text); i++ {
+		switch text[i] {
+		case '\n', '\r':
+			return true
+		case ' ', '\t':
+			continue
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// boundaryBefore checks if a rune starts a new normalization segment.
+// ASCII characters, spaces and removed characters always do.
+func boundaryBefore(nf norm.Form, r rune) bool {
+	if r < 0x80 || r == softHyphen || isInvisible(r) ||
+		unicode.Is(unicode.Zs, r) {
+		return true
+	}
+	return nf.PropertiesString(string(r)).BoundaryBefore()
+}
@@ -0,0 +1,78 @@
+package preprocess_test
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/preprocess"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		s      string
+		form   preprocess.Form
+		hasErr bool
+	}{
+		{"", preprocess.NFC, false},
+		{"NFC", preprocess.NFC, false},
+		{"nfkc", preprocess.NFKC, false},
+		{"none", preprocess.NoForm, false},
+		{"nfd", preprocess.NFC, true},
+	}
+	for _, v := range tests {
+		f, err := preprocess.New(v.s)
+		assert.Equal(v.form, f, v.s)
+		assert.Equal(v.hasErr, err != nil, v.s)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, text string
+		form      preprocess.Form
+		res       string
+	}{
+		{"ascii", "Pardosa moesta", preprocess.NFC, "Pardosa moesta"},
+		{"decomposed", "Mu\u0308ller", preprocess.NFC, "M\u00fcller"},
+		{"composed", "M\u00fcller", preprocess.NFC, "M\u00fcller"},
+		{"zero width", "Par\u200bdosa\u200d moesta", preprocess.NFC,
+			"Pardosa moesta"},
+		{"bom", "Pardosa\ufeff moesta", preprocess.NFC, "Pardosa moesta"},
+		{"soft hyphen", "Par\u00addosa", preprocess.NFC, "Pardosa"},
+		{"soft hyphen at line end", "Par\u00ad \ndosa", preprocess.NFC,
+			"Par- \ndosa"},
+		{"spaces", "Pardosa\u00a0moesta\u2009Banks\u3000x", preprocess.NFC,
+			"Pardosa moesta Banks x"},
+		{"ligature nfc", "\ufb01lum", preprocess.NFC, "\ufb01lum"},
+		{"ligature nfkc", "\ufb01lum", preprocess.NFKC, "filum"},
+		{"none", "Mu\u0308ller\u00a0x", preprocess.NoForm, "Mu\u0308ller\u00a0x"},
+	}
+	for _, v := range tests {
+		res := preprocess.Normalize([]rune(v.text), v.form)
+		assert.Equal(v.res, string(res.Text), v.msg)
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	assert := assert.New(t)
+	// decomposed "u" with diaeresis is composed, zero-width space is removed
+	text := []rune("Mu\u0308ller \u200bPardosa")
+	res := preprocess.Normalize(text, preprocess.NFC)
+	assert.Equal("M\u00fcller Pardosa", string(res.Text))
+	// "ü" comes from two runes of the original
+	assert.Equal(1, res.OriginStart(1))
+	assert.Equal(3, res.OriginEnd(2))
+	// "Pardosa" starts after the zero-width space
+	assert.Equal(9, res.OriginStart(7))
+	assert.Equal(16, res.OriginEnd(14))
+	assert.Equal(16, res.OriginEnd(20))
+	assert.Equal(7, res.Position(9))
+	assert.Equal([][2]int{{7, 14}}, res.Spans([][2]int{{8, 16}}))
+
+	res = preprocess.Normalize([]rune("Pardosa"), preprocess.NFC)
+	assert.Equal(3, res.OriginStart(3))
+	assert.Equal(7, res.OriginEnd(7))
+	assert.Equal(3, res.Position(3))
+}
@@ -0,0 +1,191 @@
+// Package section splits scientific papers into sections by their
+// headings. Names in different sections play different roles: in
+// References they are citations, in identification keys they are couplets,
+// in Materials and Methods they are records.
+package section
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Section is a part of a document that starts with a common heading.
+type Section int
+
+// Supported sections.
+const (
+	NoSection Section = iota
+	Abstract
+	Introduction
+	Methods
+	Results
+	Discussion
+	Key
+	References
+	Acknowledgements
+)
+
+var sectionStrings = [...]string{
+	"", "abstract", "introduction", "methods", "results", "discussion",
+	"key", "references", "acknowledgements",
+}
+
+// String representation of a Section.
+func (s Section) String() string {
+	return sectionStrings[s]
+}
+
+// New takes a string and returns a matching section. Headings like
+// "literature" or "danksagung" are accepted as aliases. If the string is
+// unknown, it returns NoSection and an error.
+func New(s string) (Section, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, v := range sectionStrings[1:] {
+		if s == v {
+			return Section(i + 1), nil
+		}
+	}
+	if res, ok := headings[s]; ok {
+		return res, nil
+	}
+	return NoSection, fmt.Errorf("unknown section %s", s)
+}
+
+// SectionStrings returns string representations of supported sections.
+func SectionStrings() []string {
+	res := slices.Clone(sectionStrings[1:])
+	slices.Sort(res)
+	return res
+}
+
+// Span is a section of a text.
+type Span struct {
+	Section Section
+
+	// Start of the section heading in the text.
+	Start int
+
+	// End of the section, it is the start of the next heading or the end
+	// of the text.
+	End int
+}
+
+// maxHeadingWords is the maximal number of words in a heading line.
+const maxHeadingWords = 8
+
+// numberingRe matches numbers of headings like "2.", "2.1" or "IV.".
+var numberingRe = regexp.MustCompile(`^(?:\d+(?:\.\d+)*|[IVX]+)\.?\s+`)
+
+// headings maps normalized headings to sections. English and German
+// headings are supported.
+var headings = map[string]Section{
+	"abstract":                  Abstract,
+	"summary":                   Abstract,
+	"zusammenfassung":           Abstract,
+	"kurzfassung":               Abstract,
+	"introduction":              Introduction,
+	"background":                Introduction,
+	"einleitung":                Introduction,
+	"einführung":                Introduction,
+	"methods":                   Methods,
+	"methodology":               Methods,
+	"materials and methods":     Methods,
+	"material and methods":      Methods,
+	"methods and materials":     Methods,
+	"methoden":                  Methods,
+	"material und methoden":     Methods,
+	"material und methode":      Methods,
+	"results":                   Results,
+	"results and discussion":    Results,
+	"ergebnisse":                Results,
+	"ergebnisse und diskussion": Results,
+	"taxonomy":                  Results,
+	"systematics":               Results,
+	"taxonomic account":         Results,
+	"systematic account":        Results,
+	"taxonomic treatment":       Results,
+	"systematik":                Results,
+	"taxonomie":                 Results,
+	"discussion":                Discussion,
+	"conclusion":                Discussion,
+	"conclusions":               Discussion,
+	"diskussion":                Discussion,
+	"schlussfolgerungen":        Discussion,
+	"key":                       Key,
+	"identification key":        Key,
+	"bestimmungsschlüssel":      Key,
+	"schlüssel":                 Key,
+	"references":                References,
+	"references cited":          References,
+	"literature":                References,
+	"literature cited":          References,
+	"bibliography":              References,
+	"literatur":                 References,
+	"literaturverzeichnis":      References,
+	"schriftenverzeichnis":      References,
+	"acknowledgements":          Acknowledgements,
+	"acknowledgments":           Acknowledgements,
+	"acknowledgement":           Acknowledgements,
+	"acknowledgment":            Acknowledgements,
+	"danksagung":                Acknowledgements,
+	"dank":                      Acknowledgements,
+}
+
+// keyPrefixes start headings of identification keys, for example "Key to
+// the species of Pardosa".
+var keyPrefixes = []string{"key to ", "schlüssel zu", "bestimmungsschlüssel "}
+
+// Find splits a text into sections. A section starts at a line that
+// contains only its heading, optionally numbered, and lasts until the next
+// heading. The text before the first heading is not returned.
+func Find(text []rune) []Span {
+	var res []Span
+	start := 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && text[i] != '\n' && text[i] != '\f' {
+			continue
+		}
+		if s := headingSection(string(text[start:i])); s != NoSection {
+			if len(res) > 0 {
+				res[len(res)-1].End = start
+			}
+			res = append(res, Span{Section: s, Start: start, End: len(text)})
+		}
+		start = i + 1
+	}
+	return res
+}
+
+// Of returns the section of a position in the text.
+func Of(spans []Span, i int) Section {
+	for _, v := range spans {
+		if i >= v.Start && i < v.End {
+			return v.Section
+		}
+	}
+	return NoSection
+}
+
+// headingSection returns the section that starts with the line, or
+// NoSection if the line is not a heading.
+func headingSection(line string) Section {
+	line = strings.TrimSpace(line)
+	line = numberingRe.ReplaceAllString(line, "")
+	line = strings.TrimRight(line, ".: ")
+	if line == "" || len(strings.Fields(line)) > maxHeadingWords {
+		return NoSection
+	}
+	line = strings.ToLower(strings.Join(strings.Fields(line), " "))
+	line = strings.ReplaceAll(line, " & ", " and ")
+	if s, ok := headings[line]; ok {
+		return s
+	}
+	for _, v := range keyPrefixes {
+		if strings.HasPrefix(line, v) {
+			return Key
+		}
+	}
+	return NoSection
+}
@@ -0,0 +1,68 @@
+package section_test
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/section"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		s   string
+		res section.Section
+		err bool
+	}{
+		{"references", section.References, false},
+		{"Key", section.Key, false},
+		{"literature", section.References, false},
+		{"danksagung", section.Acknowledgements, false},
+		{"appendix", section.NoSection, true},
+	}
+	for _, v := range tests {
+		res, err := section.New(v.s)
+		assert.Equal(v.res, res, v.s)
+		assert.Equal(v.err, err != nil, v.s)
+	}
+	assert.Equal(8, len(section.SectionStrings()))
+}
+
+func TestFind(t *testing.T) {
+	assert := assert.New(t)
+	text := "Spiders of Ohio\n" +
+		"ABSTRACT\nWe found Pardosa moesta.\n" +
+		"1. Introduction\nSpiders are common.\n" +
+		"2.1 Materials & Methods:\nSpecimens of Bubo bubo.\n" +
+		"Key to the species of Pardosa\n1. Legs long...Pardosa moesta\n" +
+		"Ergebnisse\nNichts.\n" +
+		"The results are discussed below.\n" +
+		"Literature cited\nBanks N. 1892. Pardosa moesta."
+	runes := []rune(text)
+	spans := section.Find(runes)
+	var sections, starts []string
+	for _, v := range spans {
+		sections = append(sections, v.Section.String())
+		starts = append(starts, string(runes[v.Start:v.Start+4]))
+	}
+	assert.Equal([]string{
+		"abstract", "introduction", "methods", "key", "results", "references",
+	}, sections)
+	assert.Equal([]string{"ABST", "1. I", "2.1 ", "Key ", "Erge", "Lite"}, starts)
+	assert.Equal(len(runes), spans[len(spans)-1].End)
+	assert.Equal(spans[1].Start, spans[0].End)
+
+	assert.Equal(section.NoSection, section.Of(spans, 3))
+	assert.Equal(section.Abstract, section.Of(spans, 30))
+	assert.Equal(section.References, section.Of(spans, len(runes)-3))
+
+	// "Material examined" starts a part of a species account
+	text = "Methods\nSpiders were collected.\nResults\nPardosa moesta\n" +
+		"Material examined.\n3 females.\nMaterial\n2 males.\n"
+	spans = section.Find([]rune(text))
+	sections = sections[:0]
+	for _, v := range spans {
+		sections = append(sections, v.Section.String())
+	}
+	assert.Equal([]string{"methods", "results"}, sections)
+}
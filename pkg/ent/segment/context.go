@@ -0,0 +1,100 @@
+package segment
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ContextMode is a way to find the context of a name.
+type ContextMode int
+
+// Supported context modes.
+const (
+	// Words context is the given number of words before and after a name.
+	Words ContextMode = iota
+
+	// Sentence context is the sentence of a name.
+	Sentence
+
+	// Paragraph context is the paragraph of a name.
+	Paragraph
+
+	// Characters context is the given number of characters before and
+	// after a name, trimmed to whole words.
+	Characters
+)
+
+var contextModeStrings = [...]string{
+	"words", "sentence", "paragraph", "characters",
+}
+
+// String representation of a ContextMode.
+func (m ContextMode) String() string {
+	return contextModeStrings[m]
+}
+
+// NewContextMode takes a string and returns a matching context mode. Empty
+// string returns Words, "chars" is an alias of "characters". If the string
+// is unknown, it returns Words and an error.
+func NewContextMode(s string) (ContextMode, error) {
+	s = strings.ToLower(s)
+	switch s {
+	case "":
+		return Words, nil
+	case "chars":
+		return Characters, nil
+	}
+	for i, v := range contextModeStrings {
+		if s == v {
+			return ContextMode(i), nil
+		}
+	}
+	return Words, fmt.Errorf("unknown context mode %s", s)
+}
+
+// ContextModeStrings returns string representations of supported context
+// modes.
+func ContextModeStrings() []string {
+	res := slices.Clone(contextModeStrings[:])
+	slices.Sort(res)
+	return res
+}
+
+// Enclosing returns the span of sentences or paragraphs that contain the
+// text from start to end. Usually it is one span, but a name might
+// continue to the next sentence, if segmentation failed. If there are no
+// such spans, it returns the span from start to end.
+func Enclosing(spans []Span, start, end int) Span {
+	res := Span{Start: start, End: end}
+	if i := Of(spans, start); i > -1 {
+		res.Start = spans[i].Start
+		res.End = max(res.End, spans[i].End)
+	}
+	if i := Of(spans, end-1); i > -1 {
+		res.End = max(res.End, spans[i].End)
+	}
+	return res
+}
+
+// Window returns the span of up to size characters before and after the
+// text from start to end. Words cut by the window are dropped, and the
+// window never continues to another paragraph.
+func Window(text []rune, start, end, size int) Span {
+	res := Span{Start: max(0, start-size), End: min(len(text), end+size)}
+	if res.Start > 0 && !unicode.IsSpace(text[res.Start-1]) {
+		for res.Start < start && !unicode.IsSpace(text[res.Start]) {
+			res.Start++
+		}
+	}
+	if res.End < len(text) && !unicode.IsSpace(text[res.End]) {
+		for res.End > end && !unicode.IsSpace(text[res.End-1]) {
+			res.End--
+		}
+	}
+	// paragraphs of the window are trimmed, so the window is trimmed too.
+	ps := Paragraphs(text[res.Start:res.End])
+	s := Enclosing(ps, start-res.Start, end-res.Start)
+	return Span{Start: res.Start + s.Start, End: res.Start + s.End}
+}
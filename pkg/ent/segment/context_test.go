@@ -0,0 +1,68 @@
+package segment_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/segment"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContextMode(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		s   string
+		res segment.ContextMode
+		err bool
+	}{
+		{"", segment.Words, false},
+		{"Sentence", segment.Sentence, false},
+		{"paragraph", segment.Paragraph, false},
+		{"chars", segment.Characters, false},
+		{"characters", segment.Characters, false},
+		{"page", segment.Words, true},
+	}
+	for _, v := range tests {
+		res, err := segment.NewContextMode(v.s)
+		assert.Equal(v.res, res, v.s)
+		assert.Equal(v.err, err != nil, v.s)
+	}
+	assert.Equal(4, len(segment.ContextModeStrings()))
+}
+
+func TestEnclosing(t *testing.T) {
+	assert := assert.New(t)
+	text := []rune("First one. We saw Bubo bubo. Last one.")
+	spans := segment.Sentences(text)
+	start := strings.Index(string(text), "Bubo")
+	s := segment.Enclosing(spans, start, start+9)
+	assert.Equal("We saw Bubo bubo.", string(text[s.Start:s.End]))
+
+	s = segment.Enclosing(spans, 8, 14)
+	assert.Equal("First one. We saw Bubo bubo.", string(text[s.Start:s.End]))
+
+	s = segment.Enclosing(nil, 18, 27)
+	assert.Equal("Bubo bubo", string(text[s.Start:s.End]))
+}
+
+func TestWindow(t *testing.T) {
+	assert := assert.New(t)
+	text := "Header\n\nThe owls like Bubo bubo very much indeed.\n\nEnd"
+	start := strings.Index(text, "Bubo")
+	end := start + 9
+	tests := []struct {
+		size int
+		res  string
+	}{
+		{0, "Bubo bubo"},
+		{3, "Bubo bubo"},
+		{6, "like Bubo bubo very"},
+		{9, "like Bubo bubo very"},
+		{100, "The owls like Bubo bubo very much indeed."},
+	}
+	runes := []rune(text)
+	for _, v := range tests {
+		s := segment.Window(runes, start, end, v.size)
+		assert.Equal(v.res, string(runes[s.Start:s.End]), v.size)
+	}
+}
@@ -0,0 +1,176 @@
+// Package segment splits a text into paragraphs and sentences. Scientific
+// texts are full of abbreviations, like "P. moesta", "C. L. Koch", "Fig. 3"
+// or "et al.", so a period ends a sentence only if it does not end an
+// abbreviation, and the next word does not start with a lower-case letter.
+package segment
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Span is a paragraph or a sentence of a text.
+type Span struct {
+	// Start of the span in the text.
+	Start int
+
+	// End of the span in the text.
+	End int
+}
+
+// abbreviations are common abbreviations of English, German and
+// taxonomic texts without their final periods. Single letters, like
+// abbreviated genera or initials of authors, are abbreviations as well.
+var abbreviations = map[string]struct{}{
+	"abb": {}, "abt": {}, "ad": {}, "aff": {}, "al": {}, "approx": {},
+	"auct": {}, "bd": {}, "bzw": {}, "ca": {}, "cf": {}, "cit": {},
+	"coll": {}, "comb": {}, "comm": {}, "d.h": {}, "det": {}, "dr": {},
+	"e.g": {}, "ed": {}, "eds": {}, "emend": {}, "etc": {}, "ex": {},
+	"excl": {}, "fam": {}, "fig": {}, "figs": {}, "gen": {}, "ggf": {},
+	"i.e": {}, "ibid": {}, "incl": {}, "ined": {}, "juv": {}, "leg": {},
+	"loc": {}, "mr": {}, "mrs": {}, "mt": {}, "no": {}, "nom": {},
+	"nov": {}, "nr": {}, "obs": {}, "op": {}, "pers": {}, "pl": {},
+	"pp": {}, "prof": {}, "s": {}, "s.l": {}, "s.lat": {}, "s.str": {},
+	"sect": {}, "sp": {}, "spec": {}, "spp": {}, "ssp": {}, "st": {},
+	"stat": {}, "subg": {}, "subsp": {}, "syn": {}, "tab": {}, "u.a": {},
+	"var": {}, "vgl": {}, "viz": {}, "vol": {}, "vs": {}, "z.b": {},
+	"zw": {},
+}
+
+// Paragraphs splits a text into paragraphs separated by empty lines or by
+// form feeds. Spans do not include spaces around paragraphs.
+func Paragraphs(text []rune) []Span {
+	var res []Span
+	start := 0
+	add := func(end int) {
+		if s, ok := trim(text, start, end); ok {
+			res = append(res, s)
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\f':
+			add(i)
+			start = i + 1
+		case '\n':
+			j := i + 1
+			for j < len(text) && isLineSpace(text[j]) {
+				j++
+			}
+			if j < len(text) && text[j] == '\n' {
+				add(i)
+				start = j + 1
+				i = j
+			}
+		}
+	}
+	add(len(text))
+	return res
+}
+
+// Sentences splits a text into sentences. Sentences never continue from
+// one paragraph to another. Spans do not include spaces around sentences.
+func Sentences(text []rune) []Span {
+	var res []Span
+	for _, p := range Paragraphs(text) {
+		start := p.Start
+		for i := p.Start; i < p.End; i++ {
+			if !isSentenceEnd(text, p.Start, p.End, i) {
+				continue
+			}
+			end := i + 1
+			for end < p.End && isClosing(text[end]) {
+				end++
+			}
+			if s, ok := trim(text, start, end); ok {
+				res = append(res, s)
+			}
+			start = end
+			i = end - 1
+		}
+		if s, ok := trim(text, start, p.End); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Of returns the index of the span that contains the position i, or -1 if
+// there is no such span. Spans have to be sorted.
+func Of(spans []Span, i int) int {
+	j := sort.Search(len(spans), func(k int) bool { return spans[k].End > i })
+	if j < len(spans) && spans[j].Start <= i {
+		return j
+	}
+	return -1
+}
+
+// isSentenceEnd checks if a character at the position i ends a sentence
+// of a paragraph that starts at start and ends at end.
+func isSentenceEnd(text []rune, start, end, i int) bool {
+	switch text[i] {
+	case '!', '?':
+	case '.':
+		if isAbbreviation(text, start, i) {
+			return false
+		}
+	default:
+		return false
+	}
+	j := i + 1
+	for j < end && isClosing(text[j]) {
+		j++
+	}
+	if j == end {
+		return true
+	}
+	if !unicode.IsSpace(text[j]) {
+		return false
+	}
+	for j < end && unicode.IsSpace(text[j]) {
+		j++
+	}
+	return j == end || !unicode.IsLower(text[j])
+}
+
+// isAbbreviation checks if a period at the position i ends an
+// abbreviation.
+func isAbbreviation(text []rune, start, i int) bool {
+	j := i
+	for j > start && !unicode.IsSpace(text[j-1]) && !isOpening(text[j-1]) {
+		j--
+	}
+	word := string(text[j:i])
+	if word == "" {
+		return false
+	}
+	if len([]rune(word)) == 1 {
+		return unicode.IsLetter([]rune(word)[0])
+	}
+	_, ok := abbreviations[strings.ToLower(word)]
+	return ok
+}
+
+// trim removes spaces around a span, and returns false for empty spans.
+func trim(text []rune, start, end int) (Span, bool) {
+	for start < end && unicode.IsSpace(text[start]) {
+		start++
+	}
+	for end > start && unicode.IsSpace(text[end-1]) {
+		end--
+	}
+	return Span{Start: start, End: end}, start < end
+}
+
+func isLineSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r'
+}
+
+func isClosing(r rune) bool {
+	return strings.ContainsRune(`)]"'’”»`, r)
+}
+
+func isOpening(r rune) bool {
+	return strings.ContainsRune(`([“‘«`, r)
+}
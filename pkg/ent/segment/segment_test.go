@@ -0,0 +1,64 @@
+package segment_test
+
+import (
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/segment"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParagraphs(t *testing.T) {
+	assert := assert.New(t)
+	text := "  First line.\nSecond line.\n \t\nNext paragraph.\fPage two.\n\n\n"
+	runes := []rune(text)
+	var res []string
+	for _, v := range segment.Paragraphs(runes) {
+		res = append(res, string(runes[v.Start:v.End]))
+	}
+	assert.Equal([]string{
+		"First line.\nSecond line.", "Next paragraph.", "Page two.",
+	}, res)
+	assert.Nil(segment.Paragraphs([]rune(" \n\n ")))
+}
+
+func TestSentences(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, text string
+		res       []string
+	}{
+		{"simple", "Spiders hunt. Do they? Yes!",
+			[]string{"Spiders hunt.", "Do they?", "Yes!"}},
+		{"genus", "We found P. moesta here. It is common.",
+			[]string{"We found P. moesta here.", "It is common."}},
+		{"authors", "Pardosa moesta C. L. Koch, 1847. Next.",
+			[]string{"Pardosa moesta C. L. Koch, 1847.", "Next."}},
+		{"abbr", "See Fig. 3 and Smith et al. Bubo bubo (e.g. Ohio).",
+			[]string{"See Fig. 3 and Smith et al. Bubo bubo (e.g. Ohio)."}},
+		{"german", "Vgl. Abb. 2 z.B. Bubo bubo. Ende.",
+			[]string{"Vgl. Abb. 2 z.B. Bubo bubo.", "Ende."}},
+		{"lower", "Found in 1892. and later.", []string{"Found in 1892. and later."}},
+		{"quotes", `He said "Bubo bubo." Then left.`,
+			[]string{`He said "Bubo bubo."`, "Then left."}},
+		{"decimal", "It is 3.5 mm long. Next.",
+			[]string{"It is 3.5 mm long.", "Next."}},
+		{"paragraph", "No period\n\nNew one.", []string{"No period", "New one."}},
+	}
+	for _, v := range tests {
+		runes := []rune(v.text)
+		var res []string
+		for _, s := range segment.Sentences(runes) {
+			res = append(res, string(runes[s.Start:s.End]))
+		}
+		assert.Equal(v.res, res, v.msg)
+	}
+}
+
+func TestOf(t *testing.T) {
+	assert := assert.New(t)
+	text := []rune("One. Two.")
+	spans := segment.Sentences(text)
+	assert.Equal(0, segment.Of(spans, 0))
+	assert.Equal(-1, segment.Of(spans, 4))
+	assert.Equal(1, segment.Of(spans, 5))
+}
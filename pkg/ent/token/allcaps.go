@@ -0,0 +1,106 @@
+package token
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gnames/gnfinder/pkg/io/dict"
+)
+
+// NormalizeAllCaps finds runs of upper-case words, like "PARDOSA MOESTA"
+// in headings, and converts words of the runs that are known specific
+// epithets or ranks to low case, if they follow a genus or another
+// converted word. Other words keep their capitalized form, so the run can
+// be processed like a normal text.
+func NormalizeAllCaps(ts []TokenSN, d *dict.Dictionary) {
+	for i := 0; i < len(ts); i++ {
+		if !isAllCaps(ts[i]) {
+			continue
+		}
+		j := i + 1
+		for j < len(ts) && isAllCaps(ts[j]) {
+			j++
+		}
+		if j-i < 2 {
+			continue
+		}
+		var lowered bool
+		for k := i; k < j; k++ {
+			ts[k].Features().AllCaps = true
+			if k > i && (lowered || isGenus(ts[k-1], d)) &&
+				isLowCaseWord(ts[k], d) {
+				lowerCase(ts[k])
+				lowered = true
+				continue
+			}
+			lowered = false
+		}
+		i = j - 1
+	}
+}
+
+// isAllCaps checks if all letters of a token are upper-case. Words must
+// have at least two letters, abbreviations like "P." are also accepted.
+func isAllCaps(t TokenSN) bool {
+	var letters int
+	for _, r := range t.Raw() {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if !unicode.IsUpper(r) {
+			return false
+		}
+		letters++
+	}
+	return letters > 1 || (letters == 1 && t.Features().Abbr)
+}
+
+// isGenus checks if a token is a known genus or an abbreviated genus.
+func isGenus(t TokenSN, d *dict.Dictionary) bool {
+	if t.Features().Abbr {
+		return true
+	}
+	for _, m := range []map[string]struct{}{d.InGenera, d.InAmbigGenera} {
+		if _, ok := m[t.Cleaned()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isLowCaseWord checks if an upper-case word is a specific epithet or
+// a rank.
+func isLowCaseWord(t TokenSN, d *dict.Dictionary) bool {
+	if _, ok := d.Ranks[strings.ToLower(string(t.Raw()))]; ok {
+		return true
+	}
+	_, ok := d.InSpecies[strings.ToLower(t.Cleaned())]
+	return ok
+}
+
+// lowerCase recomputes a token as if it was written in low case. Only
+// features that depend on the case of the token are changed, other
+// features, like a correction of a historic text, are kept.
+func lowerCase(t TokenSN) {
+	ts, ok := t.(*tokenSN)
+	if !ok {
+		return
+	}
+	f := ts.features
+	cleaned := ts.Cleaned()
+	ts.features = Features{}
+	ts.processRaw([]rune(strings.ToLower(string(ts.Raw()))))
+	lf := ts.features
+	// OCR corrections of historic texts are not in the raw token.
+	if f.Corrected && !lf.Abbr {
+		ts.SetCleaned(strings.ToLower(cleaned))
+	}
+	f.IsCapitalized = lf.IsCapitalized
+	f.Abbr = lf.Abbr
+	f.PotentialBinomialGenus = lf.PotentialBinomialGenus
+	f.StartsWithLetter = lf.StartsWithLetter
+	f.EndsWithLetter = lf.EndsWithLetter
+	f.Corrected = f.Corrected || lf.Corrected
+	f.AllCaps = true
+	ts.features = f
+}
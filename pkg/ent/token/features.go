@@ -40,6 +40,41 @@ type Features struct {
 	// RankLike is true if token is a known infraspecific rank
 	RankLike bool
 
+	// RankSuffix is a rank of a suprageneric name inferred from the ending
+	// of a capitalized token, for example "family" for "Lycosidae". Bayes'
+	// training data do not have it, so it raises odds by a fixed ratio.
+	RankSuffix string
+
+	// HybridSign is true if the token is a standalone hybrid sign '×'.
+	HybridSign bool
+
+	// HybridPrefix is true if the token starts with the hybrid sign, for
+	// example "×Triticosecale".
+	HybridPrefix bool
+
+	// Hybrid is set for the first token of a name candidate if the name is
+	// a nothogenus or a nothospecies.
+	Hybrid Hybrid
+
+	// Qualifier is set if the token is an open nomenclature qualifier
+	// like "cf.", "aff.", "sp.".
+	Qualifier Qualifier
+
+	// AllCaps is true if the token belongs to a run of upper-case words,
+	// for example "PARDOSA MOESTA" in a heading.
+	AllCaps bool
+
+	// IsItalic is true if the token is italicized in a marked-up text.
+	IsItalic bool
+
+	// InTable is true if the token is inside of a table-like region of
+	// a running text.
+	InTable bool
+
+	// Corrected is true if the cleaned token was changed by normalization
+	// of a historic text.
+	Corrected bool
+
 	// UninomialDict defines which Genera or Uninomials dictionary (if any)
 	// contained the token.
 	UninomialDict dict.DictionaryType
@@ -47,6 +82,10 @@ type Features struct {
 	// SpeciesDict defines which Species dictionary (if any) contained the token.
 	SpeciesDict dict.DictionaryType
 
+	// FuzzyMatch is a dictionary word that is one edit away from the token.
+	// It is set if UninomialDict or SpeciesDict are fuzzy.
+	FuzzyMatch string
+
 	// GenSpInAmbigDict shows how many specific/infraspecific epithets of a putative
 	// name matched bi-/tri- nomials in a full name dictionary for grey genera.
 	// For example "Bubo bubo" name would set it to 1, and "Bubo bubo bubo" would
@@ -125,6 +164,23 @@ func (p *Features) SetUninomialDict(cleaned string, d *dict.Dictionary) {
 	}
 }
 
+// SetFuzzyUninomialDict looks up a word that is not in the dictionaries
+// among genera and uninomials that are one edit away from it.
+func (p *Features) SetFuzzyUninomialDict(cleaned string, d *dict.Dictionary) {
+	if p.UninomialDict != dict.NotInDictionary {
+		return
+	}
+	if w, ok := d.FuzzyGenus(cleaned); ok {
+		p.UninomialDict = dict.FuzzyInGenus
+		p.FuzzyMatch = w
+		return
+	}
+	if w, ok := d.FuzzyUninomial(cleaned); ok {
+		p.UninomialDict = dict.FuzzyInUninomial
+		p.FuzzyMatch = w
+	}
+}
+
 func (p *Features) SetSpeciesDict(cleaned string, d *dict.Dictionary) {
 	if p.SpeciesDict != dict.NotSet {
 		return
@@ -144,6 +200,18 @@ func (p *Features) SetSpeciesDict(cleaned string, d *dict.Dictionary) {
 	}
 }
 
+// SetFuzzySpeciesDict looks up a word that is not in the dictionaries
+// among specific epithets that are one edit away from it.
+func (p *Features) SetFuzzySpeciesDict(cleaned string, d *dict.Dictionary) {
+	if p.SpeciesDict != dict.NotInDictionary {
+		return
+	}
+	if w, ok := d.FuzzySpecies(cleaned); ok {
+		p.SpeciesDict = dict.FuzzyInSpecies
+		p.FuzzyMatch = w
+	}
+}
+
 func (p *Features) SetRank(raw string, d *dict.Dictionary) {
 	if _, ok := d.Ranks[raw]; ok {
 		p.RankLike = true
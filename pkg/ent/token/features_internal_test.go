@@ -3,6 +3,7 @@ package token
 import (
 	"testing"
 
+	"github.com/gnames/gnfinder/pkg/io/dict"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -123,3 +124,55 @@ func TestEndsWithLetter(t *testing.T) {
 		})
 	}
 }
+
+func TestHistoricRaw(t *testing.T) {
+	tests := []struct {
+		msg, raw, res string
+		changed       bool
+	}{
+		{"long s", "moeſta", "moesta", true},
+		{"ligature ae", "Cæsalpinia", "Caesalpinia", true},
+		{"ligature fi", "ﬁlix", "filix", true},
+		{"diacritics", "moésta,", "moesta,", true},
+		{"combining mark", "moésta", "moesta", true},
+		{"no change", "moesta", "moesta", false},
+	}
+
+	for _, v := range tests {
+		t.Run(v.msg, func(t *testing.T) {
+			res, changed := historicRaw([]rune(v.raw))
+			assert.Equal(t, v.res, string(res))
+			assert.Equal(t, v.changed, changed)
+		})
+	}
+}
+
+func TestOCRVariants(t *testing.T) {
+	res := ocrVariants("rnoli")
+	assert.Contains(t, res, "moli")
+	assert.Contains(t, res, "rnoh")
+	assert.Equal(t, 2, len(res))
+}
+
+func TestLowerCase(t *testing.T) {
+	assert := assert.New(t)
+	d, err := dict.LoadDictionary()
+	assert.Nil(err)
+	ts := Tokenize([]rune("PARDOSA MOESTA"))
+	f := ts[1].Features()
+	f.Corrected = true
+	f.IsItalic = true
+	NormalizeAllCaps(ts, d)
+	assert.Equal("moesta", ts[1].Cleaned())
+	f = ts[1].Features()
+	assert.True(f.AllCaps)
+	assert.True(f.Corrected)
+	assert.True(f.IsItalic)
+	assert.False(f.IsCapitalized)
+	assert.True(f.StartsWithLetter)
+
+	ts = TokenizeHistoric([]rune("PARDOSA MŒSTA"), d)
+	NormalizeAllCaps(ts, d)
+	assert.Equal("moesta", ts[1].Cleaned())
+	assert.True(ts[1].Features().Corrected)
+}
@@ -0,0 +1,166 @@
+package token
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	gner "github.com/gnames/gner/ent/token"
+	"github.com/gnames/gnfinder/pkg/io/dict"
+	"golang.org/x/text/unicode/norm"
+)
+
+// historicLetters are old letters and ligatures with their modern
+// equivalents.
+var historicLetters = map[rune]string{
+	'ſ': "s", 'ꝛ': "r", 'æ': "ae", 'Æ': "Ae", 'œ': "oe", 'Œ': "Oe",
+	'ﬀ': "ff", 'ﬁ': "fi", 'ﬂ': "fl", 'ﬃ': "ffi", 'ﬄ': "ffl", 'ﬅ': "st",
+	'ﬆ': "st",
+}
+
+// ocrConfusions are pairs of letter sequences that OCR often mistakes
+// for each other.
+var ocrConfusions = [][2]string{
+	{"rn", "m"}, {"m", "rn"}, {"li", "h"}, {"h", "li"}, {"ii", "u"},
+	{"u", "ii"}, {"cl", "d"}, {"d", "cl"}, {"vv", "w"},
+}
+
+// NewHistoricTokenSN is a factory similar to NewTokenSN. Tokens created
+// by it normalize old letters, ligatures and broken diacritics.
+func NewHistoricTokenSN(token gner.TokenNER) gner.TokenNER {
+	t := &tokenSN{
+		TokenNER: token,
+		historic: true,
+	}
+	return t
+}
+
+// historicRaw substitutes old letters and ligatures with modern ones and
+// removes diacritics. It returns false if the raw token did not change.
+func historicRaw(raw []rune) ([]rune, bool) {
+	var changed bool
+	res := make([]rune, 0, len(raw))
+	for _, r := range raw {
+		if s, ok := historicLetters[r]; ok {
+			res = append(res, []rune(s)...)
+			changed = true
+			continue
+		}
+		if r < unicode.MaxASCII {
+			res = append(res, r)
+			continue
+		}
+		for _, d := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) || unicode.Is(unicode.Sk, d) {
+				changed = true
+				continue
+			}
+			res = append(res, d)
+		}
+	}
+	if !changed || len(res) == 0 {
+		return raw, false
+	}
+	return res, true
+}
+
+// correctOCR substitutes cleaned tokens that are not in the dictionaries
+// with their OCR-confusion variants that are. Genera and uninomials are
+// checked for capitalized tokens, and specific epithets for low-case
+// tokens that follow capitalized ones.
+func correctOCR(ts []TokenSN, d *dict.Dictionary) {
+	for i, t := range ts {
+		f := t.Features()
+		cleaned := t.Cleaned()
+		if f.Abbr || len(cleaned) < 3 || strings.ContainsRune(cleaned, '�') {
+			continue
+		}
+		var v string
+		if f.IsCapitalized {
+			v = ocrUninomial(cleaned, d)
+		} else if f.StartsWithLetter && afterCapitalized(ts, i) {
+			v = ocrSpecies(cleaned, d)
+		}
+		if v != "" {
+			t.SetCleaned(v)
+			f.Corrected = true
+		}
+	}
+}
+
+// afterCapitalized checks if one of a few tokens before i is capitalized.
+func afterCapitalized(ts []TokenSN, i int) bool {
+	limit := 3
+	for j := i - 1; j >= 0 && j >= i-limit; j-- {
+		if ts[j].Features().IsCapitalized {
+			return true
+		}
+	}
+	return false
+}
+
+func ocrUninomial(cleaned string, d *dict.Dictionary) string {
+	in := func(s string) bool {
+		for _, m := range []map[string]struct{}{d.InGenera, d.InAmbigGenera,
+			d.InUninomials, d.InAmbigUninomials} {
+			if _, ok := m[s]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	if in(cleaned) {
+		return ""
+	}
+	if _, ok := d.CommonWords[strings.ToLower(cleaned)]; ok {
+		return ""
+	}
+	_, size := utf8.DecodeRuneInString(cleaned)
+	first, rest := cleaned[:size], strings.ToLower(cleaned[size:])
+	for _, v := range ocrVariants(rest) {
+		if in(first + v) {
+			return first + v
+		}
+	}
+	return ""
+}
+
+func ocrSpecies(cleaned string, d *dict.Dictionary) string {
+	in := func(s string) bool {
+		_, ok := d.InSpecies[s]
+		return ok
+	}
+	if in(cleaned) {
+		return ""
+	}
+	if _, ok := d.InAmbigSpecies[cleaned]; ok {
+		return ""
+	}
+	if _, ok := d.CommonWords[cleaned]; ok {
+		return ""
+	}
+	for _, v := range ocrVariants(cleaned) {
+		if in(v) {
+			return v
+		}
+	}
+	return ""
+}
+
+// ocrVariants creates versions of a word where one of the OCR-confusion
+// sequences is substituted.
+func ocrVariants(s string) []string {
+	var res []string
+	for _, c := range ocrConfusions {
+		for i := 0; i < len(s); {
+			j := strings.Index(s[i:], c[0])
+			if j < 0 {
+				break
+			}
+			j += i
+			res = append(res, s[:j]+c[1]+s[j+len(c[0]):])
+			i = j + 1
+		}
+	}
+	return res
+}
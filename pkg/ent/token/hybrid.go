@@ -0,0 +1,29 @@
+package token
+
+// Hybrid defines kinds of hybrid names.
+type Hybrid int
+
+// Possible Hybrids
+const (
+	NoHybrid Hybrid = iota
+	Nothogenus
+	Nothospecies
+	HybridFormula
+)
+
+var hybridsStrings = [...]string{"", "NOTHOGENUS", "NOTHOSPECIES",
+	"HYBRID_FORMULA"}
+
+// String representation of a Hybrid.
+func (h Hybrid) String() string {
+	return hybridsStrings[h]
+}
+
+// hybridSign is the multiplication sign used in hybrid names.
+const hybridSign = '×'
+
+// IsHybridSign returns true if the token is a standalone hybrid sign. A
+// lower-case 'x' is often used instead of '×', so it is also accepted.
+func IsHybridSign(t TokenSN) bool {
+	return t.Features().HybridSign || string(t.Raw()) == "x"
+}
@@ -0,0 +1,33 @@
+package token
+
+import "unicode"
+
+// SetItalics marks tokens which first letter is inside of an italicized
+// fragment of a marked-up text. Italics are sorted start and end
+// positions of the fragments.
+func SetItalics(ts []TokenSN, italics [][2]int) {
+	var j int
+	for _, t := range ts {
+		pos := firstLetter(t)
+		for j < len(italics) && italics[j][1] <= pos {
+			j++
+		}
+		if j == len(italics) {
+			return
+		}
+		if pos >= italics[j][0] {
+			t.Features().IsItalic = true
+		}
+	}
+}
+
+// firstLetter returns the position of the first letter of a token in the
+// text.
+func firstLetter(t TokenSN) int {
+	for i, r := range t.Raw() {
+		if unicode.IsLetter(r) {
+			return t.Start() + i
+		}
+	}
+	return t.Start()
+}
@@ -0,0 +1,64 @@
+package token
+
+// Qualifier defines open nomenclature qualifiers that indicate
+// uncertain or incomplete identification of a taxon.
+type Qualifier int
+
+// Possible Qualifiers
+const (
+	NoQualifier Qualifier = iota
+	Cf
+	Aff
+	Sp
+	Spp
+	SpIndet
+)
+
+var qualifiersStrings = [...]string{"", "cf.", "aff.", "sp.", "spp.",
+	"sp. indet."}
+
+// String representation of a Qualifier. It is a normalized form of
+// the qualifier.
+func (q Qualifier) String() string {
+	return qualifiersStrings[q]
+}
+
+// setQualifier checks if a token with index i is an open nomenclature
+// qualifier. If it is, the qualifier is saved in the token's features.
+// Qualifiers "sp." and "spp." that are followed by "n." or "nov." are
+// nomenclatural annotations, not qualifiers.
+func setQualifier(ts []TokenSN, i int) Qualifier {
+	f := ts[i].Features()
+	if f.IsCapitalized {
+		return NoQualifier
+	}
+
+	var next TokenSN
+	if i+1 < len(ts) {
+		next = ts[i+1]
+	}
+
+	switch ts[i].Cleaned() {
+	case "cf", "cfr":
+		f.Qualifier = Cf
+	case "aff":
+		f.Qualifier = Aff
+	case "sp", "spp":
+		if next != nil && isNov(next.Cleaned()) {
+			return NoQualifier
+		}
+		f.Qualifier = Sp
+		if ts[i].Cleaned() == "spp" {
+			f.Qualifier = Spp
+		}
+		if f.Qualifier == Sp && next != nil && next.Cleaned() == "indet" {
+			f.Qualifier = SpIndet
+			next.Features().Qualifier = SpIndet
+		}
+	}
+	return f.Qualifier
+}
+
+func isNov(s string) bool {
+	return s == "n" || s == "nv" || s == "nov"
+}
@@ -0,0 +1,18 @@
+package token
+
+// SetTables marks tokens that start inside of table-like regions of
+// a text. Tables are sorted start and end positions of the regions.
+func SetTables(ts []TokenSN, tables [][2]int) {
+	var j int
+	for _, t := range ts {
+		for j < len(tables) && tables[j][1] <= t.Start() {
+			j++
+		}
+		if j == len(tables) {
+			return
+		}
+		if t.Start() >= tables[j][0] {
+			t.Features().InTable = true
+		}
+	}
+}
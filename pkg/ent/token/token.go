@@ -5,6 +5,7 @@
 package token
 
 import (
+	"strings"
 	"unicode"
 
 	"github.com/gnames/bayes/ent/feature"
@@ -31,6 +32,10 @@ type tokenSN struct {
 	// decision tags the first token of a possible name with a classification
 	// decision.
 	decision Decision
+
+	// historic is true if the token comes from a historic text, and
+	// needs normalization of old letters and ligatures.
+	historic bool
 }
 
 // NLP collects data received from Bayes' algorithm
@@ -52,6 +57,7 @@ type Indices struct {
 	Species      int
 	Rank         int
 	Infraspecies int
+	Qualifier    int
 }
 
 // NewTokenSN is a factory and a wrapper. It takes gner.TokenNER object and
@@ -92,26 +98,47 @@ func (t *tokenSN) SetDecision(d Decision) {
 // needed for scientific names finding. The function sets cleand up version of
 // raw token value and computes several properties of a token.
 func (t *tokenSN) ProcessToken() {
-	raw := t.Raw()
-	l := len(raw)
+	t.processRaw(t.Raw())
+}
+
+// processRaw computes the cleaned value and properties of a token from
+// a raw value.
+func (t *tokenSN) processRaw(raw []rune) {
 	f := &t.features
+	if t.historic {
+		if hraw, ok := historicRaw(raw); ok {
+			raw = hraw
+			f.Corrected = true
+		}
+	}
+	if raw[0] == hybridSign {
+		if len(raw) == 1 {
+			f.HybridSign = true
+		} else {
+			// hybrid sign is attached to the word, we ignore it for
+			// computing the word's features.
+			f.HybridPrefix = true
+			raw = raw[1:]
+		}
+	}
+	l := len(raw)
 
 	f.HasStartParens = raw[0] == rune('(')
 	f.HasEndParens = raw[l-1] == rune(')')
 
 	res, start, end := normalize(raw, f)
 
-	f.setAbbr(t.Raw(), start, end)
+	f.setAbbr(raw, start, end)
 	if f.IsCapitalized {
 		res[0] = unicode.ToUpper(res[0])
-		f.setPotentialBinomialGenus(t.Raw(), start, end)
+		f.setPotentialBinomialGenus(raw, start, end)
 		if f.Abbr {
 			res = append(res, rune('.'))
 		}
 	} else {
 		// makes it impossible to have capitalized species
 		f.setStartsWithLetter(start, end)
-		f.setEndsWithLetter(t.Raw(), start, end)
+		f.setEndsWithLetter(raw, start, end)
 	}
 
 	t.SetCleaned(string(res))
@@ -150,33 +177,48 @@ func normalize(raw []rune, f *Features) ([]rune, int, int) {
 // SetIndices takes a slice of tokens that correspond to a name candidate.
 // It analyses the tokens and sets Token.Indices according to feasibility
 // of the input tokens to form a scientific name. It checks if there is
-// a possible species, ranks, and infraspecies.
-func SetIndices(ts []TokenSN, d *dict.Dictionary) {
+// a possible species, ranks, and infraspecies. If fuzzy is true, words
+// that are not in the dictionaries are matched to dictionary words that
+// are one edit away from them.
+func SetIndices(ts []TokenSN, d *dict.Dictionary, fuzzy bool) {
 	u := ts[0]
 	uF := u.Features()
 	uF.SetUninomialDict(u.Cleaned(), d)
+	if fuzzy {
+		uF.SetFuzzyUninomialDict(u.Cleaned(), d)
+	}
 	l := len(ts)
 
 	if !uF.PotentialBinomialGenus || l == 1 {
 		return
 	}
 
-	if l == 2 {
-		sp := ts[1]
-		spF := sp.Features()
-		if !spF.StartsWithLetter || spF.IsCapitalized || len(sp.Cleaned()) < 3 {
+	iSp := 1
+	spF := ts[1].Features()
+	if l > 2 && spF.HasStartParens && spF.HasEndParens {
+		iSp = 2
+	}
+
+	var notho bool
+	if IsHybridSign(ts[iSp]) && iSp+1 < l {
+		notho = true
+		iSp++
+	}
+
+	switch setQualifier(ts, iSp) {
+	case NoQualifier:
+	case Cf, Aff:
+		u.Indices().Qualifier = iSp
+		iSp++
+		if iSp == l {
 			return
 		}
-		u.Indices().Species = 1
-		spF.SetSpeciesDict(sp.Cleaned(), d)
+	default:
+		// "sp.", "spp." qualifiers mean that the name is on a genus level.
+		u.Indices().Qualifier = iSp
 		return
 	}
 
-	spF := ts[1].Features()
-	iSp := 1
-	if spF.HasStartParens && spF.HasEndParens {
-		iSp = 2
-	}
 	sp := ts[iSp]
 	spF = sp.Features()
 	if !spF.StartsWithLetter ||
@@ -185,7 +227,13 @@ func SetIndices(ts []TokenSN, d *dict.Dictionary) {
 	}
 
 	u.Indices().Species = iSp
+	if notho || spF.HybridPrefix {
+		uF.Hybrid = Nothospecies
+	}
 	sp.Features().SetSpeciesDict(sp.Cleaned(), d)
+	if fuzzy {
+		sp.Features().SetFuzzySpeciesDict(sp.Cleaned(), d)
+	}
 
 	if !sp.Features().EndsWithLetter || l == iSp+1 {
 		return
@@ -209,10 +257,17 @@ func SetIndices(ts []TokenSN, d *dict.Dictionary) {
 	u.Indices().Infraspecies = iIsp
 	isp := ts[iIsp]
 	isp.Features().SetSpeciesDict(isp.Cleaned(), d)
+	if fuzzy {
+		isp.Features().SetFuzzySpeciesDict(isp.Cleaned(), d)
+	}
 }
 
 func checkRank(t TokenSN, d *dict.Dictionary) bool {
-	t.Features().SetRank(string(t.Raw()), d)
+	raw := string(t.Raw())
+	if t.Features().AllCaps {
+		raw = strings.ToLower(raw)
+	}
+	t.Features().SetRank(raw, d)
 	return t.Features().RankLike
 }
 
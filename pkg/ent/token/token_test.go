@@ -48,3 +48,17 @@ func TestTokenizeBadLetters(t *testing.T) {
 	assert.Equal(t, ts[2].Cleaned(), "S�me")
 	assert.Equal(t, ts[3].Cleaned(), "Ida�s")
 }
+
+func TestTokenizeHybrid(t *testing.T) {
+	assert := assert.New(t)
+	str := "× Triticosecale Salix ×rubens"
+	ts := token.Tokenize([]rune(str))
+	assert.Equal(4, len(ts))
+	assert.True(ts[0].Features().HybridSign)
+	assert.True(token.IsHybridSign(ts[0]))
+	assert.Equal("Triticosecale", ts[1].Cleaned())
+	assert.False(ts[1].Features().HybridPrefix)
+	assert.Equal("rubens", ts[3].Cleaned())
+	assert.True(ts[3].Features().HybridPrefix)
+	assert.True(ts[3].Features().StartsWithLetter)
+}
@@ -1,12 +1,32 @@
 package token
 
 import (
+	"slices"
+
 	gner "github.com/gnames/gner/ent/token"
+	"github.com/gnames/gnfinder/pkg/io/dict"
 )
 
 // Tokenize creates a slice containing every word in the document tokenized.
 func Tokenize(text []rune) []TokenSN {
-	gts := gner.Tokenize(text, NewTokenSN)
+	return tokenize(text, NewTokenSN)
+}
+
+// TokenizeHistoric creates a slice of tokens for texts with historic
+// spelling and OCR errors, for example old scans from the Biodiversity
+// Heritage Library. Cleaned versions of tokens have normalized letters
+// and ligatures, and OCR errors corrected using the dictionaries.
+func TokenizeHistoric(text []rune, d *dict.Dictionary) []TokenSN {
+	res := tokenize(text, NewHistoricTokenSN)
+	correctOCR(res, d)
+	return res
+}
+
+func tokenize(
+	text []rune,
+	newToken func(gner.TokenNER) gner.TokenNER,
+) []TokenSN {
+	gts := gner.Tokenize(pageBreaks(text), newToken)
 	res := make([]TokenSN, len(gts))
 	for i := range gts {
 		t := gts[i].(TokenSN)
@@ -14,3 +34,19 @@ func Tokenize(text []rune) []TokenSN {
 	}
 	return res
 }
+
+// pageBreaks substitutes form feeds that separate pages, for example in
+// texts extracted from PDF files, with new lines. The tokenizer does not
+// treat form feeds as spaces. The substitution does not change offsets.
+func pageBreaks(text []rune) []rune {
+	if !slices.Contains(text, '\f') {
+		return text
+	}
+	res := slices.Clone(text)
+	for i := range res {
+		if res[i] == '\f' {
+			res[i] = '\n'
+		}
+	}
+	return res
+}
@@ -3,16 +3,24 @@ package gnfinder
 import (
 	"cmp"
 	"log/slog"
+	"regexp"
 	"slices"
 	"time"
 
 	"github.com/gnames/bayes"
 	"github.com/gnames/bayes/ent/feature"
 	"github.com/gnames/gnfinder/pkg/config"
+	"github.com/gnames/gnfinder/pkg/ent/checklist"
+	"github.com/gnames/gnfinder/pkg/ent/graph"
 	"github.com/gnames/gnfinder/pkg/ent/heuristic"
 	"github.com/gnames/gnfinder/pkg/ent/lang"
+	"github.com/gnames/gnfinder/pkg/ent/markup"
 	"github.com/gnames/gnfinder/pkg/ent/nlp"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/gnames/gnfinder/pkg/ent/order"
 	"github.com/gnames/gnfinder/pkg/ent/output"
+	"github.com/gnames/gnfinder/pkg/ent/preprocess"
+	"github.com/gnames/gnfinder/pkg/ent/section"
 	"github.com/gnames/gnfinder/pkg/ent/token"
 	"github.com/gnames/gnfinder/pkg/io/dict"
 	"github.com/gnames/gnfmt"
@@ -41,6 +49,7 @@ func New(
 	weights map[lang.Language]bayes.Bayes,
 ) GNfinder {
 	var err error
+	cfg.OffsetUnit = cfg.EffectiveOffsetUnit()
 	gnf := &gnfinder{
 		Config:       cfg,
 		Dictionary:   dictionaries,
@@ -60,29 +69,86 @@ func New(
 // Find takes a text as a slice of bytes, detects names and returns the found
 // names. Name of the file is used for metainformation, not for opening it.
 func (gnf gnfinder) Find(file, txt string) output.Output {
+	return gnf.FindWithLayout(file, txt, output.Layout{})
+}
+
+// FindWithLayout detects names in a text extracted from a paged document,
+// for example a PDF file. Found names get page numbers and bounding boxes
+// from the layout.
+func (gnf gnfinder) FindWithLayout(
+	file, txt string,
+	layout output.Layout,
+) output.Output {
 	start := time.Now()
 	// Remove BOM if it is still around
 	if len(txt) > 3 && txt[0:3] == "\xef\xbb\xbf" {
 		txt = txt[3:]
 	}
 	text := []rune(string(txt))
-	tokens := token.Tokenize(text)
-
+	var mt markup.Text
+	if gnf.Markup != markup.NoMarkup {
+		mt = markup.Parse(text, gnf.Markup)
+		text = mt.Text
+	}
+	nt := preprocess.Normalize(text, gnf.UnicodeForm)
+	text = nt.Text
+	ct := preprocess.Text{Text: text}
+	if gnf.WithLayoutCleanup {
+		ct = preprocess.Cleanup(text, gnf.pageMarker(), gnf.Dictionary.Known)
+		text = ct.Text
+	}
 	if gnf.Language == lang.None {
 		gnf.Language, gnf.LanguageDetected = lang.DetectLanguage(text)
 	}
 
-	heuristic.TagTokens(tokens, gnf.Dictionary)
-	if gnf.WithBayes {
-		nb := gnf.bayesWeights[gnf.Language]
-		nlp.TagTokens(tokens, gnf.Dictionary, nb, gnf.BayesOddsThreshold)
+	// line positions and offset units are calculated after offsets refer
+	// to the original text.
+	cfg := gnf.GetConfig()
+	cfg.OffsetUnit = offset.Rune
+	cfg.WithLinePositions = false
+	italics := ct.Spans(nt.Spans(mt.Italics))
+	var o output.Output
+	if gnf.Checklist == checklist.NoChecklist {
+		var tables [][2]int
+		if gnf.WithTables {
+			tables = checklist.Tables(text)
+		}
+		tokens := gnf.tagTokens(text, italics, tables, false)
+		o = output.TokensToOutput(tokens, text, Version, cfg)
+	} else {
+		o = gnf.findInCells(text, italics, cfg)
+	}
+	o.AddSections(section.Find(text), gnf.ExcludeSections)
+	if gnf.WithHierarchy && gnf.Checklist == checklist.NoChecklist {
+		o.AddHierarchy(text)
+	}
+	if gnf.GraphWindow != graph.NoGraph {
+		o.AddGraph(text, gnf.GraphWindow, gnf.GraphTokens)
+	}
+	o.AddContext(text, gnf.ContextMode, gnf.ContextSize)
+	o.WithLinePositions = gnf.WithLinePositions
+	o.MapOffsets(ct.OriginStart, ct.OriginEnd)
+	// masked ranges refer to the text before cleanup
+	o.MaskedRanges = maskedRanges(ct.Masked)
+	o.MapOffsets(nt.OriginStart, nt.OriginEnd)
+	if gnf.Markup != markup.NoMarkup {
+		o.MapOffsets(mt.OriginStart, mt.OriginEnd)
+	}
+	if gnf.WithLayoutCleanup {
+		// cleanup might remove form feeds and page markers between pages
+		o.AddPages([]rune(txt))
+	}
+	if gnf.WithLinePositions {
+		o.AddLinePositions([]rune(txt))
+	}
+	o.AddLayout(layout)
+	if gnf.OffsetUnit != offset.Rune {
+		o.ConvertOffsets([]rune(txt), gnf.OffsetUnit)
 	}
-
-	o := output.TokensToOutput(tokens, text, Version, gnf.GetConfig())
 
 	o.InputFile = file
 	if gnf.WithUniqueNames {
-		o = uniqueNames(o)
+		o = uniqueNames(o, gnf.WithAggregatedNames, gnf.UniqueNamesOrder)
 	}
 	if gnf.IncludeInputText && gnf.Format != gnfmt.CSV {
 		o.InputText = txt
@@ -111,35 +177,237 @@ func (gnf gnfinder) GetVersion() gnvers.Version {
 	return gnvers.Version{Version: Version, Build: Build}
 }
 
-func uniqueNames(o output.Output) output.Output {
+// tagTokens tokenizes a text and decides which tokens are parts of names.
+// Italics and tables are regions of the text that change odds of names.
+func (gnf gnfinder) tagTokens(
+	text []rune,
+	italics, tables [][2]int,
+	isChecklist bool,
+) []token.TokenSN {
+	d := gnf.Dictionary
+	var tokens []token.TokenSN
+	if gnf.WithHistoricText {
+		tokens = token.TokenizeHistoric(text, d)
+	} else {
+		tokens = token.Tokenize(text)
+	}
+	if gnf.WithAllCaps {
+		token.NormalizeAllCaps(tokens, d)
+	}
+	token.SetItalics(tokens, italics)
+	token.SetTables(tokens, tables)
+
+	heuristic.TagTokens(tokens, d, gnf.WithFuzzyMatch)
+	if !gnf.WithBayes {
+		return tokens
+	}
+	nb := gnf.bayesWeights[gnf.Language]
+	if isChecklist {
+		nlp.TagChecklistTokens(tokens, d, nb, gnf.BayesOddsThreshold,
+			gnf.ItalicsOdds)
+	} else {
+		nlp.TagTokens(tokens, d, nb, gnf.BayesOddsThreshold, gnf.ItalicsOdds)
+	}
+	return tokens
+}
+
+// findInCells finds names in every line of a checklist or in every cell of
+// a table separately, so names never continue from one cell to another.
+func (gnf gnfinder) findInCells(
+	text []rune,
+	italics [][2]int,
+	cfg config.Config,
+) output.Output {
+	res := output.TokensToOutput(nil, nil, Version, cfg)
+	for _, c := range checklist.Cells(text, gnf.Checklist, gnf.ChecklistColumn) {
+		cellText := text[c.Start:c.End]
+		tokens := gnf.tagTokens(cellText, cellItalics(italics, c), nil, true)
+		o := output.TokensToOutput(tokens, cellText, Version, cfg)
+		shift := func(i int) int { return i + c.Start }
+		o.MapOffsets(shift, shift)
+		res.AddCell(o, c.Row, c.Column)
+	}
+	return res
+}
+
+// cellItalics returns italicized fragments of a cell with positions
+// relative to the start of the cell.
+func cellItalics(italics [][2]int, c checklist.Cell) [][2]int {
+	var res [][2]int
+	for _, v := range italics {
+		if v[1] <= c.Start || v[0] >= c.End {
+			continue
+		}
+		res = append(res, [2]int{
+			max(v[0], c.Start) - c.Start,
+			min(v[1], c.End) - c.Start,
+		})
+	}
+	return res
+}
+
+// pageMarker returns a compiled regular expression of the page marker,
+// or nil if the marker is not set or invalid.
+func (gnf gnfinder) pageMarker() *regexp.Regexp {
+	if gnf.PageMarker == "" {
+		return nil
+	}
+	re, err := regexp.Compile(gnf.PageMarker)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+func maskedRanges(ms []preprocess.Mask) []output.MaskedRange {
+	if len(ms) == 0 {
+		return nil
+	}
+	res := make([]output.MaskedRange, len(ms))
+	for i, v := range ms {
+		res[i] = output.MaskedRange{
+			Kind:        v.Kind,
+			OffsetStart: v.Start,
+			OffsetEnd:   v.End,
+		}
+	}
+	return res
+}
+
+// uniqueNames keeps the first occurrence of every name. If aggregate is
+// true, names also get counts and offsets of all their occurrences,
+// verbatim variants, and the maximal odds and cardinality. A unique name
+// keeps the nomenclatural annotation and type material of its first
+// annotated occurrence, and relations link unique names instead of their
+// occurrences.
+func uniqueNames(
+	o output.Output,
+	aggregate bool,
+	ord order.Order,
+) output.Output {
 	if len(o.Names) == 0 {
 		return o
 	}
 
-	namesMap := make(map[string]output.Name)
+	// names keep the order of their first appearance.
+	var names []output.Name
+	idx := make(map[string]int)
 	for _, v := range o.Names {
-		if _, ok := namesMap[v.Name]; !ok {
-			name := output.Name{
-				Cardinality:  v.Cardinality,
-				Name:         v.Name,
-				OddsLog10:    v.OddsLog10,
-				OddsDetails:  v.OddsDetails,
-				OffsetStart:  v.OffsetStart,
-				OffsetEnd:    v.OffsetEnd,
-				Verification: v.Verification,
-			}
-			namesMap[v.Name] = name
+		i, ok := idx[v.Name]
+		if !ok {
+			i = len(names)
+			idx[v.Name] = i
+			names = append(names, output.Name{
+				Cardinality:   v.Cardinality,
+				Name:          v.Name,
+				ExpandedName:  v.ExpandedName,
+				SuggestedName: v.SuggestedName,
+				OddsLog10:     v.OddsLog10,
+				OddsDetails:   v.OddsDetails,
+				OffsetStart:   v.OffsetStart,
+				OffsetEnd:     v.OffsetEnd,
+				LineStart:     v.LineStart,
+				ColStart:      v.ColStart,
+				LineEnd:       v.LineEnd,
+				ColEnd:        v.ColEnd,
+				Page:          v.Page,
+				Section:       v.Section,
+				IsHeading:     v.IsHeading,
+				HeadingTaxon:  v.HeadingTaxon,
+				InTable:       v.InTable,
+				BoundingBoxes: v.BoundingBoxes,
+				Hybrid:        v.Hybrid,
+				Qualifier:     v.Qualifier,
+				Rank:          v.Rank,
+				Verification:  v.Verification,
+
+				ExpandedAmbiguous: v.ExpandedAmbiguous,
+				HybridParents:     v.HybridParents,
+
+				Context:          v.Context,
+				ContextStart:     v.ContextStart,
+				ContextEnd:       v.ContextEnd,
+				ContextNameStart: v.ContextNameStart,
+				ContextNameEnd:   v.ContextNameEnd,
+			})
+		}
+		n := &names[i]
+		n.Count++
+		uniqueAnnot(n, v)
+		if aggregate {
+			aggregateName(n, v)
 		}
 	}
-	names := make([]output.Name, len(namesMap))
-	var count int
-	for _, v := range namesMap {
-		names[count] = v
-		count++
+
+	switch ord {
+	case order.Alphabetical:
+		slices.SortFunc(names, func(a, b output.Name) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	case order.Frequency:
+		slices.SortStableFunc(names, func(a, b output.Name) int {
+			if a.Count != b.Count {
+				return b.Count - a.Count
+			}
+			return cmp.Compare(a.Name, b.Name)
+		})
 	}
-	slices.SortFunc(names, func(a, b output.Name) int {
-		return cmp.Compare(a.Name, b.Name)
-	})
+	if !aggregate {
+		for i := range names {
+			names[i].Count = 0
+		}
+	}
+	o.Relations = uniqueRelations(o.Relations, o.Names, names)
 	o.Names = names
 	return o
 }
+
+// uniqueAnnot gives a unique name the nomenclatural annotation and type
+// material of an occurrence, if the unique name has no annotation yet.
+func uniqueAnnot(n *output.Name, v output.Name) {
+	if n.AnnotNomenType != "" && n.AnnotNomenType != output.NoAnnot.String() {
+		return
+	}
+	n.AnnotNomen = v.AnnotNomen
+	n.AnnotNomenType = v.AnnotNomenType
+	n.AnnotNomenStart = v.AnnotNomenStart
+	n.AnnotNomenEnd = v.AnnotNomenEnd
+	n.TypeMaterial = v.TypeMaterial
+}
+
+// uniqueRelations changes indices of relations from occurrences of names
+// to unique names.
+func uniqueRelations(
+	rels []output.Relation,
+	occurs, names []output.Name,
+) []output.Relation {
+	if len(rels) == 0 {
+		return rels
+	}
+	idx := make(map[string]int, len(names))
+	for i, v := range names {
+		idx[v.Name] = i
+	}
+	res := make([]output.Relation, len(rels))
+	for i, v := range rels {
+		v.NameIndex = idx[occurs[v.NameIndex].Name]
+		if v.RelatedIndex >= 0 {
+			v.RelatedIndex = idx[occurs[v.RelatedIndex].Name]
+		}
+		res[i] = v
+	}
+	return res
+}
+
+// aggregateName adds an occurrence to an aggregated unique name.
+func aggregateName(n *output.Name, v output.Name) {
+	n.Occurrences = append(n.Occurrences, output.Occurrence{
+		OffsetStart: v.OffsetStart,
+		OffsetEnd:   v.OffsetEnd,
+	})
+	if !slices.Contains(n.VerbatimVariants, v.Verbatim) {
+		n.VerbatimVariants = append(n.VerbatimVariants, v.Verbatim)
+	}
+	n.OddsLog10 = max(n.OddsLog10, v.OddsLog10)
+	n.Cardinality = max(n.Cardinality, v.Cardinality)
+}
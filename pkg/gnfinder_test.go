@@ -6,15 +6,27 @@ import (
 	"log/slog"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gnames/bayes"
 	gnfinder "github.com/gnames/gnfinder/pkg"
 	"github.com/gnames/gnfinder/pkg/config"
+	"github.com/gnames/gnfinder/pkg/ent/checklist"
+	"github.com/gnames/gnfinder/pkg/ent/graph"
 	"github.com/gnames/gnfinder/pkg/ent/lang"
+	"github.com/gnames/gnfinder/pkg/ent/markup"
 	"github.com/gnames/gnfinder/pkg/ent/nlp"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/gnames/gnfinder/pkg/ent/order"
+	"github.com/gnames/gnfinder/pkg/ent/output"
+	"github.com/gnames/gnfinder/pkg/ent/preprocess"
+	"github.com/gnames/gnfinder/pkg/ent/section"
+	"github.com/gnames/gnfinder/pkg/ent/segment"
+	"github.com/gnames/gnfinder/pkg/ent/token"
 	"github.com/gnames/gnfinder/pkg/io/dict"
+	"github.com/gnames/gnfmt"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -421,29 +433,19 @@ Conostylis americana, 2i. 6d.
 	}
 }
 
-// TextTokensAroundSizeLimit tests size limitation for words before and after
-// a name.
-func TextTokensAroundSizeLimit(t *testing.T) {
+// TestTokensAroundLongWords tests that long words before and after a name
+// are kept.
+func TestTokensAroundLongWords(t *testing.T) {
 	assert := assert.New(t)
-	txt := "Aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa " +
-		"Pardosa moesta " +
-		"Bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	long := strings.Repeat("a", 40)
+	txt := "Aaaa " + long + " Pardosa moesta " + long + " bbbb cccc"
 	gnf := genFinder(t, config.OptTokensAround(2))
 	res := gnf.Find("", txt)
 	assert.Equal(2, res.WordsAround)
 
 	n := res.Names[0]
-	assert.Zero(len(n.WordsBefore))
-	assert.Zero(len(n.WordsAfter))
-
-	txt = "Aaaaaaaaaaaaaaaaaaaaaaa Pardosa moesta " +
-		"bbbbbbbbbbbbbbbbbbbbbbb"
-	gnf = genFinder(t, config.OptTokensAround(2))
-	res = gnf.Find("", txt)
-	assert.Equal(2, res.WordsAround)
-	n = res.Names[0]
-	assert.Equal(1, len(n.WordsBefore))
-	assert.Equal(1, len(n.WordsAfter))
+	assert.Equal([]string{"Aaaa", long}, n.WordsBefore)
+	assert.Equal([]string{long, "bbbb"}, n.WordsAfter)
 }
 
 // TestsTestLastName tests a situation where a name is the last thing in the
@@ -543,6 +545,510 @@ func TestNomenAnnot(t *testing.T) {
 	}
 }
 
+// TestNomenAnnotExtended tests detection of a wider range of nomenclatural
+// acts, including annotations placed before the name.
+func TestNomenAnnotExtended(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		txt, annot, annotType string
+		start, end            int
+	}{
+		{"Pardosa gen. nov.", "gen. nov.", "GEN_NOV", 8, 17},
+		{"Pardosa moesta gen.n.", "gen.n.", "GEN_NOV", 15, 21},
+		{"Lycosidae fam. nov.", "fam. nov.", "FAM_NOV", 10, 19},
+		{"Pardosa moesta stat. nov.", "stat. nov.", "STAT_NOV", 15, 25},
+		{"Pardosa moesta stat. rev.", "stat. rev.", "STAT_REV", 15, 25},
+		{"Pardosa moesta nom. nud.", "nom. nud.", "NOM_NUD", 15, 24},
+		{"Pardosa moesta nomen nudum", "nomen nudum", "NOM_NUD", 15, 26},
+		{"Pardosa moesta syn. nov.", "syn. nov.", "SYN_NOV", 15, 24},
+		{"Pardosa moesta sp. n.", "sp. n.", "SP_NOV", 15, 21},
+		{"Pardosa moesta species nova", "species nova", "SP_NOV", 15, 27},
+		{"Pardosa moesta Banks, new species", "new species", "SP_NOV",
+			22, 33},
+		{"Pardosa moesta, new combination", "new combination", "COMB_NOV",
+			16, 31},
+		{"Pardosa moesta, neue Art", "neue Art", "SP_NOV", 16, 24},
+		{"Pardosa moesta, neue Kombination", "neue Kombination", "COMB_NOV",
+			16, 32},
+		{"Genus novum: Pardosa", "Genus novum:", "GEN_NOV", 0, 12},
+		{"New species. Pardosa moesta", "New species.", "SP_NOV", 0, 12},
+		{"Results\nNew species\nPardosa moesta", "New species", "SP_NOV",
+			8, 19},
+		{"Spiders. New species: Pardosa moesta", "New species:", "SP_NOV",
+			9, 21},
+		{"Pardosa moesta nom. rev.", "", "NO_ANNOT", 0, 0},
+		{"Pardosa moesta sp. nud.", "", "NO_ANNOT", 0, 0},
+		{"Pardosa moesta Banks, 1892, sp. n.", "sp. n.", "SP_NOV", 28, 34},
+		{"Pardosa moesta (Banks & Smith, 1892) new species", "new species",
+			"SP_NOV", 37, 48},
+		{"Pardosa moesta is not a new species", "", "NO_ANNOT", 0, 0},
+		{"Pardosa moesta in the new name list", "", "NO_ANNOT", 0, 0},
+		{"Pardosa moesta and the new combination", "", "NO_ANNOT", 0, 0},
+		{"This is not a new species: Pardosa moesta", "", "NO_ANNOT", 0, 0},
+		{"We found the sp. nov. Pardosa moesta", "", "NO_ANNOT", 0, 0},
+	}
+
+	gnf := genFinder(t)
+	for _, v := range tests {
+		res := gnf.Find("", v.txt)
+		name := res.Names[0]
+		assert.Equal(v.annot, name.AnnotNomen, v.txt)
+		assert.Equal(v.annotType, name.AnnotNomenType, v.txt)
+		assert.Equal(v.start, name.AnnotNomenStart, v.txt)
+		assert.Equal(v.end, name.AnnotNomenEnd, v.txt)
+	}
+}
+
+// TestNomenAnnotUsedOnce checks that an annotation after one name is not
+// reused as an annotation before the next name, and that the annotation of
+// the next name is not taken by the previous one.
+func TestNomenAnnotUsedOnce(t *testing.T) {
+	assert := assert.New(t)
+	gnf := genFinder(t)
+	res := gnf.Find("", "Pardosa moesta sp. n. Pomatomus saltator")
+	assert.Equal(2, len(res.Names))
+	assert.Equal("SP_NOV", res.Names[0].AnnotNomenType)
+	assert.Equal("NO_ANNOT", res.Names[1].AnnotNomenType)
+
+	// annotations of the next name are not taken by the previous one.
+	for _, v := range []string{
+		"We saw Pardosa moesta. Pardosa moesta sp. nov. is here",
+		"Pardosa moesta, Pomatomus saltator sp. n.",
+		"Bubo bubo and Pomatomus saltator new species",
+	} {
+		res = gnf.Find("", v)
+		assert.Equal(2, len(res.Names), v)
+		assert.Equal("NO_ANNOT", res.Names[0].AnnotNomenType, v)
+		assert.NotEqual("NO_ANNOT", res.Names[1].AnnotNomenType, v)
+	}
+}
+
+// TestTypeMaterial tests extraction of type designations and specimen
+// codes after names with nomenclatural annotations.
+func TestTypeMaterial(t *testing.T) {
+	assert := assert.New(t)
+	txt := "Pardosa moesta sp. nov. Holotype: female (MCZ 12345). " +
+		"Paratypes: USNM-1234567, 2 males. Material examined: 3 females."
+	gnf := genFinder(t, config.OptWithTypeMaterial(true))
+	res := gnf.Find("", txt)
+	assert.Equal(1, len(res.Names))
+	tms := res.Names[0].TypeMaterial
+	assert.Equal(5, len(tms))
+
+	tests := []struct {
+		tp, verb, desig, coll, num string
+		start, end                 int
+	}{
+		{"HOLOTYPE", "Holotype", "", "", "", 24, 32},
+		{"SPECIMEN", "MCZ 12345", "HOLOTYPE", "MCZ", "12345", 42, 51},
+		{"PARATYPE", "Paratypes", "", "", "", 54, 63},
+		{"SPECIMEN", "USNM-1234567", "PARATYPE", "USNM", "1234567", 65, 77},
+		{"MATERIAL_EXAMINED", "Material examined", "", "", "", 88, 105},
+	}
+	for i, v := range tests {
+		tm := tms[i]
+		assert.Equal(v.tp, tm.Type, v.verb)
+		assert.Equal(v.verb, tm.Verbatim, v.verb)
+		assert.Equal(v.verb, txt[tm.OffsetStart:tm.OffsetEnd], v.verb)
+		assert.Equal(v.desig, tm.Designation, v.verb)
+		assert.Equal(v.coll, tm.Collection, v.verb)
+		assert.Equal(v.num, tm.CatalogNumber, v.verb)
+		assert.Equal(v.start, tm.OffsetStart, v.verb)
+		assert.Equal(v.end, tm.OffsetEnd, v.verb)
+	}
+
+	// type material is ignored for names without annotations
+	res = gnf.Find("", "Pardosa moesta Holotype: MCZ 12345.")
+	assert.Equal(0, len(res.Names[0].TypeMaterial))
+
+	// type material is not extracted by default
+	gnf = genFinder(t)
+	res = gnf.Find("", txt)
+	assert.Equal(0, len(res.Names[0].TypeMaterial))
+}
+
+// TestRelations tests detection of synonymy and other statements that
+// link found names.
+func TestRelations(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt, verb, relType string
+		namesNum, idx, related  int
+	}{
+		{"equals", "Pardosa moesta Banks = Pomatomus saltator Linnaeus",
+			"=", "SYNONYM", 2, 0, 1},
+		{"syn", "Pardosa moesta (syn. Pomatomus saltator) is here",
+			"syn.", "SYNONYM", 2, 0, 1},
+		{"non pair", "Pardosa moesta Banks non Pomatomus saltator",
+			"non", "NON", 2, 0, 1},
+		{"non author", "Pardosa moesta Banks, non Smith, 1900. Here",
+			"non Smith, 1900.", "NON", 1, 0, -1},
+		{"sensu", "Pardosa moesta sensu lato is common",
+			"sensu lato", "SENSU", 1, 0, -1},
+		{"s. l.", "Pardosa moesta s. l. is common",
+			"s. l.", "SENSU", 1, 0, -1},
+		{"auct", "Pardosa moesta auct. is common",
+			"auct.", "MISAPPLIED", 1, 0, -1},
+	}
+
+	gnf := genFinder(t)
+	for _, v := range tests {
+		res := gnf.Find("", v.txt)
+		assert.Equal(v.namesNum, len(res.Names), v.msg)
+		assert.Equal(1, len(res.Relations), v.msg)
+		if len(res.Relations) == 0 {
+			continue
+		}
+		rel := res.Relations[0]
+		assert.Equal(v.relType, rel.Type, v.msg)
+		assert.Equal(v.verb, rel.Verbatim, v.msg)
+		assert.Equal(v.verb, v.txt[rel.OffsetStart:rel.OffsetEnd], v.msg)
+		assert.Equal(v.idx, rel.NameIndex, v.msg)
+		assert.Equal(v.related, rel.RelatedIndex, v.msg)
+	}
+
+	res := gnf.Find("", "Pardosa moesta is common, and the word is used "+
+		"many times, unlike Pomatomus saltator = ")
+	assert.Equal(0, len(res.Relations))
+}
+
+// TestHistoricText tests normalization of old letters, ligatures and
+// OCR errors in historic texts.
+func TestHistoricText(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt, verb, name string
+		start, end           int
+	}{
+		{"long s", "The Pardoſa moeſta was found", "Pardoſa moeſta",
+			"Pardosa moesta", 4, 18},
+		{"ligature", "The Bœchera stricta was found", "Bœchera stricta",
+			"Boechera stricta", 4, 19},
+		{"diacritics", "The Pardosa moésta was found", "Pardosa moésta",
+			"Pardosa moesta", 4, 18},
+		{"rn as m", "The Pomatornus saltator was found",
+			"Pomatornus saltator", "Pomatomus saltator", 4, 23},
+		{"m as rn", "The Pardosa rnoesta was found", "Pardosa rnoesta",
+			"Pardosa moesta", 4, 19},
+	}
+
+	gnf := genFinder(t, config.OptWithHistoricText(true))
+	for _, v := range tests {
+		res := gnf.Find("", v.txt)
+		assert.Equal(1, len(res.Names), v.msg)
+		if len(res.Names) == 0 {
+			continue
+		}
+		name := res.Names[0]
+		assert.Equal(v.verb, name.Verbatim, v.msg)
+		assert.Equal(v.name, name.Name, v.msg)
+		assert.True(name.Corrected, v.msg)
+		assert.Equal(v.start, name.OffsetStart, v.msg)
+		assert.Equal(v.end, name.OffsetEnd, v.msg)
+	}
+
+	res := gnf.Find("", "The Pardosa moesta was found")
+	assert.False(res.Names[0].Corrected)
+
+	gnf = genFinder(t)
+	res = gnf.Find("", "The Pomatornus saltator was found")
+	assert.NotEqual("Pomatomus saltator", res.Names[0].Name)
+}
+
+// TestFuzzyMatch tests finding of misspelled names.
+func TestFuzzyMatch(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt, name, suggested string
+	}{
+		{"species typo", "The Pardosa moestta was found", "Pardosa moestta",
+			"Pardosa moesta"},
+		{"genus typo", "The Parosa moesta was found", "Parosa moesta",
+			"Pardosa moesta"},
+		{"transposition", "The Pardosa meosta was found", "Pardosa meosta",
+			"Pardosa moesta"},
+		{"no typo", "The Pardosa moesta was found", "Pardosa moesta", ""},
+	}
+
+	gnf := genFinder(t, config.OptWithFuzzyMatch(true))
+	for _, v := range tests {
+		res := gnf.Find("", v.txt)
+		assert.Equal(1, len(res.Names), v.msg)
+		if len(res.Names) == 0 {
+			continue
+		}
+		assert.Equal(v.name, res.Names[0].Name, v.msg)
+		assert.Equal(v.suggested, res.Names[0].SuggestedName, v.msg)
+	}
+
+	// Bayes takes likelihoods of exact matches for fuzzy matches and lowers
+	// them by a fixed ratio.
+	gnf = genFinder(t, config.OptWithFuzzyMatch(true),
+		config.OptWithBayesOddsDetails(true))
+	exact := gnf.Find("", "The Pardosa moesta was found").Names[0].Odds
+	for _, v := range []struct{ txt, dict, fuzzy string }{
+		{"The Parosa moesta was found", "uniDict: inGenus", "uniFuzzy: fixed"},
+		{"The Pardosa moestta was found", "spDict: inSpecies", "spFuzzy: fixed"},
+	} {
+		res := gnf.Find("", v.txt)
+		assert.Equal(1, len(res.Names), v.txt)
+		if len(res.Names) == 0 {
+			continue
+		}
+		assert.Greater(res.Names[0].Odds, 1.0, v.txt)
+		assert.Less(res.Names[0].Odds, exact, v.txt)
+		det := res.Names[0].OddsDetails
+		assert.Contains(det, v.dict, v.txt)
+		assert.Equal(0.001, det[v.fuzzy], v.txt)
+		for k := range det {
+			assert.NotContains(k, "Ambig", v.txt)
+		}
+	}
+
+	gnf = genFinder(t)
+	res := gnf.Find("", "The Pardosa moestta was found")
+	assert.Equal("Pardosa", res.Names[0].Name)
+	assert.Equal("", res.Names[0].SuggestedName)
+}
+
+// TestAllCaps tests finding names in upper-case headings.
+func TestAllCaps(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt, verb, name string
+		start, end           int
+	}{
+		{"binomial", "FIG. 1. PARDOSA MOESTA BANKS", "PARDOSA MOESTA",
+			"Pardosa moesta", 8, 22},
+		{"trinomial", "NOTES ON BUBO BUBO BUBO", "BUBO BUBO BUBO",
+			"Bubo bubo bubo", 9, 23},
+		{"rank", "PINUS SYLVESTRIS VAR. FRAGILIS",
+			"PINUS SYLVESTRIS VAR. FRAGILIS", "Pinus sylvestris var. fragilis",
+			0, 30},
+		{"uninomial", "FAMILY CARABIDAE", "CARABIDAE", "Carabidae", 7, 16},
+	}
+
+	gnf := genFinder(t, config.OptWithAllCaps(true))
+	for _, v := range tests {
+		res := gnf.Find("", v.txt)
+		assert.True(res.WithAllCaps, v.msg)
+		assert.Equal(1, len(res.Names), v.msg)
+		if len(res.Names) == 0 {
+			continue
+		}
+		name := res.Names[0]
+		assert.Equal(v.verb, name.Verbatim, v.msg)
+		assert.Equal(v.name, name.Name, v.msg)
+		assert.Equal(v.start, name.OffsetStart, v.msg)
+		assert.Equal(v.end, name.OffsetEnd, v.msg)
+	}
+
+	// upper-case names are not normalized by default
+	gnf = genFinder(t)
+	res := gnf.Find("", "FIG. 1. PARDOSA MOESTA BANKS")
+	assert.False(res.WithAllCaps)
+	for _, v := range res.Names {
+		assert.NotEqual("Pardosa moesta", v.Name)
+	}
+}
+
+// TestRankSuffix tests detection of suprageneric names by their endings.
+func TestRankSuffix(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt, name, rank string
+	}{
+		{"new family", "The Pardosidae are spiders", "Pardosidae", "family"},
+		{"new subfamily", "The Moestinae are spiders", "Moestinae",
+			"subfamily"},
+		{"new superfamily", "The Pardosoidea are spiders", "Pardosoidea",
+			"superfamily"},
+		{"new order", "The Pardosales are spiders", "Pardosales", "order"},
+		{"new tribe", "The Pardosini are spiders", "Pardosini", "tribe"},
+		{"short tribe", "The tribe Pardini are spiders", "Pardini", "tribe"},
+		{"insect order", "The Hymenoptera are insects", "Hymenoptera",
+			"order"},
+		{"botanical family", "The Plantagoaceae are plants", "Plantagoaceae",
+			"family"},
+	}
+
+	for _, o := range [][]config.Option{
+		nil,
+		{config.OptWithBayes(false)},
+	} {
+		gnf := genFinder(t, o...)
+		for _, v := range tests {
+			res := gnf.Find("", v.txt)
+			assert.Equal(1, len(res.Names), v.msg)
+			if len(res.Names) == 0 {
+				continue
+			}
+			assert.Equal(v.name, res.Names[0].Name, v.msg)
+			assert.Equal(v.rank, res.Names[0].Rank, v.msg)
+			assert.Equal(token.Uninomial, res.Names[0].Decision, v.msg)
+		}
+	}
+
+	gnf := genFinder(t, config.OptWithBayesOddsDetails(true))
+	res := gnf.Find("", "The Pardosidae are spiders")
+	assert.Equal(1000.0, res.Names[0].OddsDetails["uniRankSuffix: fixed"])
+
+	gnf = genFinder(t)
+	res = gnf.Find("", "The Lycosidae are spiders")
+	assert.Equal(1, len(res.Names))
+	assert.Equal("Lycosidae", res.Names[0].Name)
+	assert.Equal("family", res.Names[0].Rank)
+
+	res = gnf.Find("", "The Pardosa moesta are spiders")
+	assert.Equal("", res.Names[0].Rank)
+
+	// Spanish and Italian surnames are not names of orders and tribes.
+	surnames := []string{
+		"Data from Perales and Zanini (2001) and Vidales",
+		"Collected by Giovannini and Morales",
+		"Specimens of Bellini, Canales and Rossini",
+	}
+	for _, o := range [][]config.Option{
+		nil,
+		{config.OptWithAmbiguousNames(true)},
+		{config.OptWithAmbiguousNames(true), config.OptWithBayes(false)},
+	} {
+		gnf = genFinder(t, o...)
+		for _, v := range surnames {
+			res = gnf.Find("", v)
+			for _, n := range res.Names {
+				assert.Empty(n.Rank, v)
+				assert.NotContains([]string{"Perales", "Zanini", "Vidales",
+					"Giovannini", "Morales", "Bellini", "Canales", "Rossini"},
+					n.Name, v)
+			}
+		}
+	}
+}
+
+// TestMarkup tests name-finding in marked-up texts.
+func TestMarkup(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt   string
+		m          markup.Markup
+		verb, name string
+		start, end int
+	}{
+		{"html new species",
+			"<p>A new species <i>Pardosa zyxwensis</i> is described.</p>",
+			markup.HTML, "Pardosa zyxwensis", "Pardosa zyxwensis", 20, 37},
+		{"html new genus",
+			"<p>The genus <em>Zyxwella</em> is new.</p>",
+			markup.HTML, "Zyxwella", "Zyxwella", 17, 25},
+		{"html entity", "<p>Sp&eacute;cies <i>Pardosa zyxwensis</i></p>",
+			markup.HTML, "Pardosa zyxwensis", "Pardosa zyxwensis", 21, 38},
+		{"markdown", "A new species *Pardosa zyxwensis* is described.",
+			markup.Markdown, "Pardosa zyxwensis", "Pardosa zyxwensis", 15, 32},
+		{"jats", "<p>A new species <italic>Pardosa zyxwensis</italic></p>",
+			markup.JATS, "Pardosa zyxwensis", "Pardosa zyxwensis", 25, 42},
+		{"known name", "<p><b>Pardosa moesta</b> Banks</p>", markup.HTML,
+			"Pardosa moesta", "Pardosa moesta", 6, 20},
+	}
+
+	for _, v := range tests {
+		gnf := genFinder(t, config.OptMarkup(v.m))
+		res := gnf.Find("", v.txt)
+		assert.Equal(1, len(res.Names), v.msg)
+		if len(res.Names) == 0 {
+			continue
+		}
+		name := res.Names[0]
+		assert.Equal(v.verb, name.Verbatim, v.msg)
+		assert.Equal(v.name, name.Name, v.msg)
+		assert.Equal(v.start, name.OffsetStart, v.msg)
+		assert.Equal(v.end, name.OffsetEnd, v.msg)
+		assert.Equal(v.verb, v.txt[name.OffsetStart:name.OffsetEnd], v.msg)
+	}
+
+	// italics that contain other words are not names
+	gnf := genFinder(t, config.OptMarkup(markup.HTML))
+	res := gnf.Find("", "<i>Zyxwella is a journal</i>")
+	assert.Equal(0, len(res.Names))
+
+	// italics raise odds of names, but do not replace them
+	for _, v := range []string{"Endeavour", "Zeitschrift"} {
+		res = gnf.Find("", "<p>The <i>"+v+"</i> is old.</p>")
+		assert.Equal(0, len(res.Names), v)
+	}
+	gnf = genFinder(t, config.OptMarkup(markup.HTML),
+		config.OptWithBayesOddsDetails(true))
+	res = gnf.Find("", "<p>The genus <em>Zyxwella</em> is new.</p>")
+	assert.Equal(10.0, res.ItalicsOdds)
+	assert.Equal(1, len(res.Names))
+	assert.Equal(10.0, res.Names[0].OddsDetails["uniItalic: fixed"])
+	assert.Greater(res.Names[0].Odds, 1.0)
+
+	// the odds ratio of italics is a setting
+	gnf = genFinder(t, config.OptMarkup(markup.HTML),
+		config.OptItalicsOdds(1))
+	res = gnf.Find("", "<p>The genus <em>Zyxwella</em> is new.</p>")
+	assert.Equal(1.0, res.ItalicsOdds)
+	assert.Equal(0, len(res.Names))
+
+	// without markup the new species is not found
+	gnf = genFinder(t)
+	res = gnf.Find("", "A new species Pardosa zyxwensis is described.")
+	for _, v := range res.Names {
+		assert.NotEqual("Pardosa zyxwensis", v.Name)
+	}
+
+	txt := "<p>Éspèce <i>Pardosa zyxwensis</i></p>"
+	gnf = genFinder(t, config.OptMarkup(markup.HTML),
+		config.OptWithPositonInBytes(true))
+	res = gnf.Find("", txt)
+	assert.Equal(1, len(res.Names))
+	name := res.Names[0]
+	assert.Equal("Pardosa zyxwensis", txt[name.OffsetStart:name.OffsetEnd])
+}
+
+// TestFindWithLayout tests pages and bounding boxes of names found in
+// paged documents.
+func TestFindWithLayout(t *testing.T) {
+	assert := assert.New(t)
+	txt := "Spiders\n\nThe Pardosa\nmoesta Banks"
+	l := output.Layout{
+		Pages: []output.Page{
+			{Number: 1, OffsetStart: 0, OffsetEnd: 7},
+			{Number: 2, OffsetStart: 9, OffsetEnd: 33},
+		},
+		Boxes: make([]output.Box, len(txt)),
+	}
+	lineBoxes := func(start, end int, y float64) {
+		for i := start; i < end; i++ {
+			x := 72 + 5*float64(i-start)
+			if txt[i] != ' ' {
+				l.Boxes[i] = output.Box{X0: x, Y0: y, X1: x + 5, Y1: y + 10}
+			}
+		}
+	}
+	lineBoxes(0, 7, 700)
+	lineBoxes(9, 20, 700)
+	lineBoxes(21, 33, 680)
+
+	gnf := genFinder(t)
+	res := gnf.FindWithLayout("", txt, l)
+	assert.Equal(2, res.PagesNum)
+	assert.Equal(1, len(res.Names))
+	name := res.Names[0]
+	assert.Equal("Pardosa moesta", name.Name)
+	assert.Equal(2, name.Page)
+	assert.Equal([]output.BoundingBox{
+		{Page: 2, Box: output.Box{X0: 92, Y0: 700, X1: 127, Y1: 710}},
+		{Page: 2, Box: output.Box{X0: 72, Y0: 680, X1: 102, Y1: 690}},
+	}, name.BoundingBoxes)
+
+	res = gnf.Find("", txt)
+	assert.Equal(0, res.PagesNum)
+	assert.Equal(0, res.Names[0].Page)
+	assert.Nil(res.Names[0].BoundingBoxes)
+}
+
 // TestNomenAnnotNoSpace covers issue #140:
 // Process correctly annotations without spaces.
 func TestNomenAnnotNoSpace(t *testing.T) {
@@ -565,6 +1071,80 @@ func TestNomenAnnotNoSpace(t *testing.T) {
 	}
 }
 
+// TestQualifiers tests detection of open nomenclature qualifiers.
+func TestQualifiers(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt, verbatim, name, qual string
+	}{
+		{"cf", "Carex cf. aquatilis", "Carex cf. aquatilis",
+			"Carex aquatilis", "cf."},
+		{"cfr", "Carex cfr. aquatilis", "Carex cfr. aquatilis",
+			"Carex aquatilis", "cf."},
+		{"aff", "Aus aff. bus", "Aus aff. bus", "Aus bus", "aff."},
+		{"sp", "Pardosa sp. from the forest", "Pardosa", "Pardosa", "sp."},
+		{"sp no period", "Pardosa sp 1", "Pardosa", "Pardosa", "sp."},
+		{"spp", "Bombus spp. were common", "Bombus", "Bombus", "spp."},
+		{"sp indet", "Pardosa sp. indet.", "Pardosa", "Pardosa", "sp. indet."},
+		{"no qualifier", "Pardosa moesta", "Pardosa moesta", "Pardosa moesta",
+			""},
+	}
+	gnf := genFinder(t)
+	for _, v := range tests {
+		res := gnf.Find("", v.txt)
+		assert.Equal(1, len(res.Names), v.msg)
+		name := res.Names[0]
+		assert.Equal(v.verbatim, name.Verbatim, v.msg)
+		assert.Equal(v.name, name.Name, v.msg)
+		assert.Equal(v.qual, name.Qualifier, v.msg)
+		assert.Equal("NO_ANNOT", name.AnnotNomenType, v.msg)
+	}
+}
+
+// TestHybrids tests detection of nothogenera, nothospecies and hybrid
+// formulas.
+func TestHybrids(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt, verbatim, name, hybrid string
+		parents                          []string
+	}{
+		{"nothogenus", "Genus × Triticosecale is", "× Triticosecale",
+			"× Triticosecale", "NOTHOGENUS", nil},
+		{"nothogenus attached", "Genus ×Triticosecale is", "×Triticosecale",
+			"× Triticosecale", "NOTHOGENUS", nil},
+		{"nothospecies", "Salix × rubens grows", "Salix × rubens",
+			"Salix × rubens", "NOTHOSPECIES", nil},
+		{"nothospecies x", "Salix x rubens grows", "Salix x rubens",
+			"Salix × rubens", "NOTHOSPECIES", nil},
+		{"nothospecies attached", "Salix ×rubens grows", "Salix ×rubens",
+			"Salix × rubens", "NOTHOSPECIES", nil},
+		{"formula", "Mentha aquatica × M. spicata is",
+			"Mentha aquatica × M. spicata",
+			"Mentha aquatica × Mentha spicata", "HYBRID_FORMULA",
+			[]string{"Mentha aquatica", "Mentha spicata"}},
+		{"not hybrid", "Salix fragilis grows", "Salix fragilis",
+			"Salix fragilis", "", nil},
+	}
+	gnf := genFinder(t)
+	for _, v := range tests {
+		res := gnf.Find("", v.txt)
+		assert.Equal(1, len(res.Names), v.msg)
+		name := res.Names[0]
+		assert.Equal(v.verbatim, name.Verbatim, v.msg)
+		assert.Equal(v.name, name.Name, v.msg)
+		assert.Equal(v.hybrid, name.Hybrid, v.msg)
+		assert.Equal(v.parents, name.HybridParents, v.msg)
+	}
+
+	// words after a hybrid formula follow its last parent
+	gnf = genFinder(t, config.OptTokensAround(3))
+	res := gnf.Find("", "Leaves of Mentha aquatica × M. spicata are used in tea")
+	assert.Equal(1, len(res.Names))
+	assert.Equal([]string{"Leaves", "of"}, res.Names[0].WordsBefore)
+	assert.Equal([]string{"are", "used", "in"}, res.Names[0].WordsAfter)
+}
+
 func TestFakeAnnot(t *testing.T) {
 	assert := assert.New(t)
 	txts := []string{
@@ -584,6 +1164,70 @@ func TestFakeAnnot(t *testing.T) {
 	}
 }
 
+// TestAbbrExpansion tests expansion of abbreviated genera using names
+// TestUniqueNamesKeepAnnotations checks that unique names keep
+// nomenclatural annotations, type material and relations.
+func TestUniqueNamesKeepAnnotations(t *testing.T) {
+	assert := assert.New(t)
+	txt := "We saw Pardosa moesta. Pardosa moesta sp. nov. Holotype: female " +
+		"(MCZ 12345). Pomatomus saltator = Bubo bubo. Bubo bubo again."
+	for _, aggregate := range []bool{false, true} {
+		gnf := genFinder(t,
+			config.OptWithUniqueNames(true),
+			config.OptWithAggregatedNames(aggregate),
+			config.OptWithTypeMaterial(true),
+		)
+		o := gnf.Find("", txt)
+		var names []string
+		for _, v := range o.Names {
+			names = append(names, v.Name)
+		}
+		assert.Equal([]string{"Bubo bubo", "Pardosa moesta",
+			"Pomatomus saltator"}, names)
+
+		pardosa := o.Names[1]
+		assert.Equal("SP_NOV", pardosa.AnnotNomenType)
+		assert.Equal("sp. nov.", txt[pardosa.AnnotNomenStart:pardosa.AnnotNomenEnd])
+		assert.Equal(2, len(pardosa.TypeMaterial))
+		assert.Equal("NO_ANNOT", o.Names[0].AnnotNomenType)
+
+		assert.Equal(1, len(o.Relations))
+		if len(o.Relations) == 0 {
+			continue
+		}
+		assert.Equal("SYNONYM", o.Relations[0].Type)
+		assert.Equal(2, o.Relations[0].NameIndex)
+		assert.Equal(0, o.Relations[0].RelatedIndex)
+	}
+}
+
+// found earlier in the text.
+func TestAbbrExpansion(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, txt, expanded string
+		ambiguous          bool
+	}{
+		{"expands", "Pardosa lapidicina and P. moesta", "Pardosa moesta", false},
+		{"no genus", "Only P. moesta here", "", false},
+		{"other letter", "Carex scirpoidea and P. moesta", "", false},
+		{"most recent",
+			"Pomatomus saltator, Pardosa lapidicina and P. moesta",
+			"Pardosa moesta", true},
+		{"two letters",
+			"Pomatomus saltator, Pardosa lapidicina and Po. saltator",
+			"Pomatomus saltator", false},
+	}
+
+	gnf := genFinder(t)
+	for _, v := range tests {
+		res := gnf.Find("", v.txt)
+		name := res.Names[len(res.Names)-1]
+		assert.Equal(v.expanded, name.ExpandedName, v.msg)
+		assert.Equal(v.ambiguous, name.ExpandedAmbiguous, v.msg)
+	}
+}
+
 func TestChangeConfig(t *testing.T) {
 	assert := assert.New(t)
 	gnf := genFinder(t)
@@ -638,6 +1282,664 @@ func TestBytesOffset(t *testing.T) {
 	}
 }
 
+func TestOffsetUnit(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, input string
+		unit       offset.Unit
+		start, end int
+	}{
+		{"ascii utf16", "Hello Pardosa moesta", offset.UTF16, 6, 20},
+		{"emoji runes", "😀 Pardosa moesta 😀", offset.Rune, 2, 16},
+		{"emoji bytes", "😀 Pardosa moesta 😀", offset.Byte, 5, 19},
+		{"emoji utf16", "😀 Pardosa moesta 😀", offset.UTF16, 3, 17},
+		{"cyrillic utf16", "Это Pardosa moesta", offset.UTF16, 4, 18},
+	}
+
+	for _, v := range tests {
+		gnf := genFinder(t, config.OptOffsetUnit(v.unit))
+		o := gnf.Find("", v.input)
+		assert.Equal(v.unit.String(), o.OffsetUnit, v.msg)
+		assert.Equal(1, len(o.Names), v.msg)
+		name := o.Names[0]
+		assert.Equal(v.start, name.OffsetStart, v.msg)
+		assert.Equal(v.end, name.OffsetEnd, v.msg)
+	}
+
+	gnf := genFinder(t,
+		config.OptOffsetUnit(offset.UTF16),
+		config.OptMarkup(markup.HTML),
+	)
+	o := gnf.Find("", "<p>😀 <i>Pardosa moesta</i></p>")
+	assert.Equal(1, len(o.Names))
+	assert.Equal(9, o.Names[0].OffsetStart)
+	assert.Equal(23, o.Names[0].OffsetEnd)
+
+	// deprecated field of Config still works
+	cfg := config.New()
+	cfg.WithPositionInBytes = true
+	gnf = gnfinder.New(cfg, dictionary, weights)
+	o = gnf.Find("", "😀 Pardosa moesta 😀")
+	assert.Equal("bytes", o.OffsetUnit)
+	assert.True(o.WithPositionInBytes)
+	assert.Equal(5, o.Names[0].OffsetStart)
+
+	// explicit offset unit is not overridden by the deprecated field
+	cfg = config.New(config.OptOffsetUnit(offset.Rune))
+	cfg.WithPositionInBytes = true
+	gnf = gnfinder.New(cfg, dictionary, weights)
+	o = gnf.Find("", "😀 Pardosa moesta 😀")
+	assert.Equal("runes", o.OffsetUnit)
+	assert.Equal(2, o.Names[0].OffsetStart)
+
+	// empty unit is not a part of JSON
+	o.OffsetUnit = ""
+	assert.NotContains(o.Format(gnfmt.CompactJSON), "offsetUnit")
+}
+
+func TestUnicodeForm(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, input string
+		form       preprocess.Form
+		verb       string
+		start, end int
+	}{
+		{"decomposed before name", "Mu\u0308ller found Pardosa moesta",
+			preprocess.NFC, "Pardosa moesta", 14, 28},
+		{"zero width in name", "The Par\u200bdosa moesta",
+			preprocess.NFC, "Pardosa moesta", 4, 19},
+		{"soft hyphen in name", "The Pardo\u00adsa moesta",
+			preprocess.NFC, "Pardosa moesta", 4, 19},
+		{"soft hyphen at line end", "The Pardo\u00ad\nsa moesta",
+			preprocess.NFC, "Pardosa moesta", 4, 20},
+		{"no-break space", "The Pardosa\u202fmoesta", preprocess.NFC,
+			"Pardosa moesta", 4, 18},
+		{"full-width", "The \uff30ardosa moesta", preprocess.NFKC,
+			"Pardosa moesta", 4, 18},
+	}
+
+	for _, v := range tests {
+		gnf := genFinder(t, config.OptUnicodeForm(v.form))
+		o := gnf.Find("", v.input)
+		assert.Equal(v.form.String(), o.UnicodeForm, v.msg)
+		assert.Equal(1, len(o.Names), v.msg)
+		if len(o.Names) == 0 {
+			continue
+		}
+		name := o.Names[0]
+		assert.Equal(v.verb, name.Name, v.msg)
+		assert.Equal(v.start, name.OffsetStart, v.msg)
+		assert.Equal(v.end, name.OffsetEnd, v.msg)
+	}
+
+	// italics of a marked-up text are moved with normalization
+	gnf := genFinder(t, config.OptMarkup(markup.HTML))
+	o := gnf.Find("", "<p>Mu\u0308ller <i>Par\u200bdosa zyxwensis</i></p>")
+	assert.Equal(1, len(o.Names))
+	assert.Equal("Pardosa zyxwensis", o.Names[0].Name)
+	assert.Equal(14, o.Names[0].OffsetStart)
+	assert.Equal(32, o.Names[0].OffsetEnd)
+
+	// without normalization zero-width space breaks the name
+	gnf = genFinder(t, config.OptUnicodeForm(preprocess.NoForm))
+	o = gnf.Find("", "The Par\u200bdosa moesta")
+	assert.Equal(1, len(o.Names))
+	assert.NotEqual("Pardosa moesta", o.Names[0].Name)
+}
+
+func TestLayoutCleanup(t *testing.T) {
+	assert := assert.New(t)
+	txt := "JOURNAL OF ARACHNOLOGY 12\nSpiders of Ohio. The Pardo-\n" +
+		"- 12 -\n\f" +
+		"JOURNAL OF ARACHNOLOGY 13\nsa moesta is common.\n" +
+		"- 13 -\n\f" +
+		"JOURNAL OF ARACHNOLOGY 14\nBubo bubo is not.\n14"
+
+	gnf := genFinder(t, config.OptWithLayoutCleanup(true))
+	o := gnf.Find("", txt)
+	assert.True(o.WithLayoutCleanup)
+	assert.Equal(3, o.PagesNum)
+	assert.Equal(2, len(o.Names))
+	name := o.Names[0]
+	assert.Equal("Pardosa moesta", name.Name)
+	assert.Equal("Pardosa moesta", name.Verbatim)
+	assert.Equal(1, name.Page)
+	assert.Equal("Pardo-", txt[name.OffsetStart:name.OffsetStart+6])
+	assert.Equal("sa moesta", txt[name.OffsetEnd-9:name.OffsetEnd])
+	assert.Equal("Bubo bubo", txt[o.Names[1].OffsetStart:o.Names[1].OffsetEnd])
+	assert.Equal(3, o.Names[1].Page)
+
+	gnf = genFinder(t)
+	o = gnf.Find("", txt)
+	assert.False(o.WithLayoutCleanup)
+	for _, v := range o.Names {
+		assert.NotEqual("Pardosa moesta", v.Name)
+	}
+}
+
+func TestPageMarker(t *testing.T) {
+	assert := assert.New(t)
+	txt := "=== Page 1 ===\nJOURNAL OF ARACHNOLOGY\nSpiders of Ohio. The Pardo-\n" +
+		"=== Page 2 ===\nJOURNAL OF ARACHNOLOGY\nsa moesta is common.\n" +
+		"=== Page 3 ===\nJOURNAL OF ARACHNOLOGY\nBubo bubo is not."
+
+	gnf := genFinder(t,
+		config.OptWithLayoutCleanup(true),
+		config.OptPageMarker(`^=+ Page \d+ =+$`),
+	)
+	o := gnf.Find("", txt)
+	assert.Equal(`^=+ Page \d+ =+$`, o.PageMarker)
+	assert.Equal(3, o.PagesNum)
+	assert.Equal(6, len(o.MaskedRanges))
+	for _, v := range o.MaskedRanges {
+		s := txt[v.OffsetStart:v.OffsetEnd]
+		switch v.Kind {
+		case "pageMarker":
+			assert.Contains(s, "=== Page")
+		case "header":
+			assert.Equal("JOURNAL OF ARACHNOLOGY\n", s)
+		default:
+			t.Errorf("unexpected masked range %s", v.Kind)
+		}
+	}
+	assert.Equal(2, len(o.Names))
+	assert.Equal("Pardosa moesta", o.Names[0].Name)
+	assert.Equal(1, o.Names[0].Page)
+	assert.Equal("Bubo bubo", o.Names[1].Name)
+	assert.Equal(3, o.Names[1].Page)
+
+	// offsets of masked ranges follow the offset unit
+	txt = "=== Page 1 ===\nÉtudes\nPardosa moesta\n" +
+		"=== Page 2 ===\nÉtudes\nBubo bubo"
+	gnf = genFinder(t,
+		config.OptWithLayoutCleanup(true),
+		config.OptPageMarker(`^=+ Page \d+ =+$`),
+		config.OptOffsetUnit(offset.Byte),
+	)
+	o = gnf.Find("", txt)
+	assert.Equal(4, len(o.MaskedRanges))
+	m := o.MaskedRanges[3]
+	assert.Equal("header", m.Kind)
+	assert.Equal("Études\n", txt[m.OffsetStart:m.OffsetEnd])
+
+	// invalid markers are ignored
+	gnf = genFinder(t, config.OptPageMarker(`[`))
+	assert.Equal("", gnf.GetConfig().PageMarker)
+}
+
+func TestChecklist(t *testing.T) {
+	assert := assert.New(t)
+	txt := "Zyxolia kerbanensis\nPardosa\nmoesta\nMeeting notes\n"
+
+	gnf := genFinder(t)
+	o := gnf.Find("", txt)
+	assert.Equal("", o.Checklist)
+	assert.Equal(1, len(o.Names))
+	assert.Equal("Pardosa moesta", o.Names[0].Name)
+
+	gnf = genFinder(t, config.OptChecklist(checklist.Lines))
+	o = gnf.Find("", txt)
+	assert.Equal("lines", o.Checklist)
+	names := make([]string, len(o.Names))
+	for i, v := range o.Names {
+		names[i] = v.Name
+		assert.Equal(v.Verbatim, txt[v.OffsetStart:v.OffsetEnd])
+	}
+	assert.Equal([]string{"Zyxolia kerbanensis", "Pardosa"}, names)
+	assert.Equal(1, o.Names[0].Row)
+	assert.Equal(2, o.Names[1].Row)
+	assert.Equal(0, o.Names[1].Column)
+
+	txt = "id,name,note\n1,\"Zyxolia kerbanensis\",Bubo bubo\n2,Pardosa moesta,"
+	gnf = genFinder(t,
+		config.OptChecklist(checklist.CSV),
+		config.OptChecklistColumn(2),
+	)
+	o = gnf.Find("", txt)
+	assert.Equal(2, o.ChecklistColumn)
+	assert.Equal(2, len(o.Names))
+	for i, v := range o.Names {
+		assert.Equal(i+2, v.Row)
+		assert.Equal(2, v.Column)
+		assert.Equal(v.Verbatim, txt[v.OffsetStart:v.OffsetEnd])
+	}
+	res := o.Format(gnfmt.CSV)
+	assert.Contains(res, "Index,Verbatim,Name,Start,End,Row,Column,")
+	assert.Contains(res, "Pardosa moesta,Pardosa moesta,49,63,3,2,")
+}
+
+func TestInTable(t *testing.T) {
+	assert := assert.New(t)
+	txt := "We found Pardosa moesta and Toxolia pellida near the lake.\n" +
+		"Pardosa moesta\t12\t3.4\n" +
+		"Bubo bubo\t7\t0.5\n" +
+		"Toxolia pellida\t2\t0.1\n" +
+		"All of them were common."
+	gnf := genFinder(t, config.OptWithTables(true))
+	o := gnf.Find("", txt)
+	assert.True(o.WithTables)
+	names := make([]string, len(o.Names))
+	for i, v := range o.Names {
+		names[i] = v.Name
+	}
+	assert.Equal(
+		[]string{"Pardosa moesta", "Pardosa moesta", "Bubo bubo", "Toxolia pellida"},
+		names,
+	)
+	assert.False(o.Names[0].InTable)
+	for _, v := range o.Names[1:] {
+		assert.True(v.InTable)
+	}
+	// prior odds of names are higher in tables
+	assert.Greater(o.Names[1].OddsLog10, o.Names[0].OddsLog10)
+
+	// names of checklists are not flagged
+	gnf = genFinder(t, config.OptWithTables(true),
+		config.OptChecklist(checklist.Lines))
+	o = gnf.Find("", txt)
+	for _, v := range o.Names {
+		assert.False(v.InTable)
+	}
+
+	// tables are not detected by default
+	gnf = genFinder(t)
+	o = gnf.Find("", txt)
+	assert.False(o.WithTables)
+	for _, v := range o.Names {
+		assert.False(v.InTable)
+	}
+
+	// reference lists are not tables
+	refs := "References\n" +
+		"Banks N. 1892. Pardosa moesta of Ohio. Proc. Acad. 12: 1-81.\n" +
+		"Emerton J. 1885. New England Lycosidae. Trans. Conn. 6: 481-505.\n" +
+		"Gertsch W. 1934. Notes on Bubo bubo. Am. Mus. Novit. 693.\n"
+	gnf = genFinder(t, config.OptWithTables(true))
+	o = gnf.Find("", refs)
+	assert.Greater(len(o.Names), 0)
+	for _, v := range o.Names {
+		assert.False(v.InTable, v.Name)
+	}
+}
+
+func TestSections(t *testing.T) {
+	assert := assert.New(t)
+	txt := "Spiders of Ohio. Pardosa moesta\n" +
+		"Results\nWe found Bubo bubo in the forest.\n" +
+		"Key to the species\n1. Legs long ... Pardosa moesta\n" +
+		"Danksagung\nWir danken Aus bus.\n" +
+		"References\nBanks N. 1892. Pardosa lapidicina of Ohio."
+
+	gnf := genFinder(t)
+	o := gnf.Find("", txt)
+	sections := make(map[string]string)
+	for _, v := range o.Names {
+		sections[v.Name+" "+v.Section] = v.Section
+	}
+	assert.Equal(map[string]string{
+		"Pardosa moesta ":               "",
+		"Bubo bubo results":             "results",
+		"Pardosa moesta key":            "key",
+		"Aus bus acknowledgements":      "acknowledgements",
+		"Pardosa lapidicina references": "references",
+	}, sections)
+
+	gnf = genFinder(t, config.OptExcludeSections(
+		[]section.Section{section.References, section.Acknowledgements},
+	))
+	o = gnf.Find("", txt)
+	assert.Equal([]string{"references", "acknowledgements"}, o.ExcludedSections)
+	names := make([]string, len(o.Names))
+	for i, v := range o.Names {
+		names[i] = v.Name
+	}
+	assert.Equal([]string{"Pardosa moesta", "Bubo bubo", "Pardosa moesta"}, names)
+
+	// relations refer to indices of reported names
+	txt = "References\nBubo bubo L.\nResults\n" +
+		"Pardosa moesta Banks = Pomatomus saltator Linnaeus"
+	o = gnf.Find("", txt)
+	assert.Equal(2, len(o.Names))
+	assert.Equal(1, len(o.Relations))
+	assert.Equal(0, o.Relations[0].NameIndex)
+	assert.Equal(1, o.Relations[0].RelatedIndex)
+
+	// "Material examined" is a part of species accounts, not of methods
+	txt = "Materials and methods\nSpiders were collected by hand.\n" +
+		"Taxonomy\nPardosa moesta Banks, 1892\n" +
+		"Material examined.\n3 females, Ohio, with Bubo bubo.\n" +
+		"Material\nTwo males from Utah, on Pomatomus saltator.\n"
+	gnf = genFinder(t, config.OptExcludeSections(
+		[]section.Section{section.Methods},
+	))
+	o = gnf.Find("", txt)
+	names = names[:0]
+	for _, v := range o.Names {
+		names = append(names, v.Name)
+		assert.NotEqual("methods", v.Section, v.Name)
+	}
+	assert.Equal(
+		[]string{"Pardosa moesta", "Bubo bubo", "Pomatomus saltator"},
+		names,
+	)
+}
+
+func TestHierarchy(t *testing.T) {
+	assert := assert.New(t)
+	txt := "Spiders of Ohio\nFamily Lycosidae Sundevall, 1833\n" +
+		"Wolf spiders are common.\nGenus Pardosa C. L. Koch, 1847\n" +
+		"1. Pardosa moesta Banks, 1892\n" +
+		"Material: Ohio. Similar to Pardosa lapidicina.\n" +
+		"2. Pardosa lapidicina Emerton, 1885\nFound with Bubo bubo."
+
+	gnf := genFinder(t)
+	o := gnf.Find("", txt)
+	assert.Nil(o.Hierarchy)
+	assert.Equal("", o.Names[1].HeadingTaxon)
+
+	gnf = genFinder(t, config.OptWithHierarchy(true))
+	o = gnf.Find("", txt)
+	assert.True(o.WithHierarchy)
+	assert.NotNil(o.Hierarchy)
+	type taxon struct {
+		name, rank string
+		parent     int
+	}
+	var taxa []taxon
+	for _, v := range o.Hierarchy.Taxa {
+		taxa = append(taxa, taxon{v.Name, v.Rank, v.ParentIndex})
+		assert.Equal(v.Name, txt[v.OffsetStart:v.OffsetEnd])
+	}
+	assert.Equal([]taxon{
+		{"Lycosidae", "family", -1},
+		{"Pardosa", "genus", 0},
+		{"Pardosa moesta", "species", 1},
+		{"Pardosa lapidicina", "species", 1},
+	}, taxa)
+
+	type name struct {
+		name, heading string
+		isHeading     bool
+	}
+	var names []name
+	for _, v := range o.Names {
+		names = append(names, name{v.Name, v.HeadingTaxon, v.IsHeading})
+	}
+	assert.Equal([]name{
+		{"Lycosidae", "", true},
+		{"Pardosa", "Lycosidae", true},
+		{"Pardosa moesta", "Pardosa", true},
+		{"Pardosa lapidicina", "Pardosa moesta", false},
+		{"Pardosa lapidicina", "Pardosa", true},
+		{"Bubo bubo", "Pardosa lapidicina", false},
+	}, names)
+
+	// German rank words
+	txt = "Familie Lycosidae\nGattung Pardosa\nPardosa moesta Banks"
+	o = gnf.Find("", txt)
+	assert.Equal(3, len(o.Hierarchy.Taxa))
+	assert.Equal("genus", o.Hierarchy.Taxa[1].Rank)
+	assert.Equal(1, o.Hierarchy.Taxa[2].ParentIndex)
+}
+
+func TestGraph(t *testing.T) {
+	assert := assert.New(t)
+	txt := "Pardosa moesta was found on Bubo bubo. P. moesta is common. " +
+		"Later Bubo bubo and Pomatomus saltator met.\n\nPomatomus saltator is everywhere."
+
+	gnf := genFinder(t)
+	o := gnf.Find("", txt)
+	assert.Nil(o.Graph)
+
+	gnf = genFinder(t, config.OptGraphWindow(graph.Sentence))
+	o = gnf.Find("", txt)
+	assert.Equal("sentence", o.Graph.Window)
+	type node struct {
+		name  string
+		count int
+	}
+	var nodes []node
+	for _, v := range o.Graph.Nodes {
+		nodes = append(nodes, node{v.Name, v.Count})
+	}
+	assert.Equal([]node{
+		{"Pardosa moesta", 2}, {"Bubo bubo", 2}, {"Pomatomus saltator", 2},
+	}, nodes)
+	assert.Equal([]graph.Edge{
+		{Source: 0, Target: 1, Weight: 1},
+		{Source: 1, Target: 2, Weight: 1},
+	}, o.Graph.Edges)
+
+	gnf = genFinder(t, config.OptGraphWindow(graph.Paragraph))
+	o = gnf.Find("", txt)
+	assert.Equal(3, len(o.Graph.Edges))
+
+	gnf = genFinder(t,
+		config.OptGraphWindow(graph.Tokens), config.OptGraphTokens(3))
+	o = gnf.Find("", txt)
+	assert.Equal(3, o.Graph.WindowTokens)
+	assert.Equal([]graph.Edge{
+		{Source: 0, Target: 1, Weight: 1},
+		{Source: 1, Target: 2, Weight: 1},
+	}, o.Graph.Edges)
+
+	// graph survives unique names
+	gnf = genFinder(t,
+		config.OptGraphWindow(graph.Sentence), config.OptWithUniqueNames(true))
+	o = gnf.Find("", txt)
+	assert.Equal(2, len(o.Graph.Edges))
+
+	// other formats turn on the sentence window
+	cfg := config.New(config.OptGraphFormat(graph.DOT))
+	assert.Equal(graph.Sentence, cfg.GraphWindow)
+	assert.Equal(10, cfg.GraphTokens)
+}
+
+func TestContext(t *testing.T) {
+	assert := assert.New(t)
+	txt := "Spiders of Ohio.\n\nWe found Pardosa moesta\nnear water. " +
+		"Smith et al. saw P. moesta too. Bubo bubo var. bubo hunts. " +
+		"Also, it eats them.\n\nThe end."
+
+	gnf := genFinder(t)
+	o := gnf.Find("", txt)
+	assert.Equal("", o.ContextMode)
+	assert.Equal("", o.Names[0].Context)
+
+	gnf = genFinder(t, config.OptContextMode(segment.Sentence))
+	o = gnf.Find("", txt)
+	assert.Equal("sentence", o.ContextMode)
+	assert.Equal(0, o.ContextSize)
+	var contexts []string
+	for _, v := range o.Names {
+		contexts = append(contexts, v.Context)
+		assert.Equal(v.Verbatim,
+			v.Context[v.ContextNameStart:v.ContextNameEnd])
+		context := strings.ReplaceAll(txt[v.ContextStart:v.ContextEnd], "\n", " ")
+		assert.Equal(context, v.Context)
+	}
+	assert.Equal([]string{
+		"We found Pardosa moesta near water.",
+		"Smith et al. saw P. moesta too.",
+		"Bubo bubo var. bubo hunts.",
+	}, contexts)
+
+	gnf = genFinder(t, config.OptContextMode(segment.Paragraph))
+	o = gnf.Find("", txt)
+	assert.True(strings.HasPrefix(o.Names[2].Context, "We found"))
+	assert.True(strings.HasSuffix(o.Names[2].Context, "it eats them."))
+
+	gnf = genFinder(t,
+		config.OptContextMode(segment.Characters), config.OptContextSize(10))
+	o = gnf.Find("", txt)
+	assert.Equal("characters", o.ContextMode)
+	assert.Equal(10, o.ContextSize)
+	assert.Equal("al. saw P. moesta too. Bubo", o.Names[1].Context)
+
+	// relative offsets follow offset units
+	txt = "Ökologie: Über Pardosa moesta. Ende."
+	gnf = genFinder(t,
+		config.OptContextMode(segment.Sentence), config.OptOffsetUnit(offset.Byte))
+	o = gnf.Find("", txt)
+	n := o.Names[0]
+	assert.Equal("Ökologie: Über Pardosa moesta.", n.Context)
+	assert.Equal(n.Verbatim, n.Context[n.ContextNameStart:n.ContextNameEnd])
+	assert.Equal(n.Verbatim, txt[n.OffsetStart:n.OffsetEnd])
+	assert.Equal(n.Context, txt[n.ContextStart:n.ContextEnd])
+
+	// contexts are kept in CSV
+	gnf = genFinder(t,
+		config.OptContextMode(segment.Sentence), config.OptFormat(gnfmt.CSV))
+	o = gnf.Find("", txt)
+	res := o.Format(gnfmt.CSV)
+	assert.Contains(res, ",Context\n")
+	assert.Contains(res, ",Ökologie: Über Pardosa moesta.")
+
+	// zero offsets of contexts are a part of JSON
+	gnf = genFinder(t, config.OptContextMode(segment.Sentence))
+	o = gnf.Find("", "Pardosa moesta is here.")
+	res = o.Format(gnfmt.CompactJSON)
+	assert.Contains(res, `"contextStart":0`)
+	assert.Contains(res, `"contextNameStart":0`)
+}
+
+func TestAggregatedNames(t *testing.T) {
+	assert := assert.New(t)
+	txt := "Pomatomus saltator eats Bubo bubo. Pardosa moesta, Pardosa " +
+		"moesta and PARDOSA MOESTA are here. Bubo bubo again."
+
+	gnf := genFinder(t, config.OptWithUniqueNames(true),
+		config.OptWithAllCaps(true))
+	o := gnf.Find("", txt)
+	assert.False(o.WithAggregatedNames)
+	assert.Equal("alphabetical", o.UniqueNamesOrder)
+	var names []string
+	for _, v := range o.Names {
+		names = append(names, v.Name)
+		assert.Equal(0, v.Count)
+		assert.Nil(v.Occurrences)
+	}
+	assert.Equal([]string{"Bubo bubo", "Pardosa moesta", "Pomatomus saltator"},
+		names)
+
+	gnf = genFinder(t,
+		config.OptWithAggregatedNames(true),
+		config.OptWithAllCaps(true),
+		config.OptUniqueNamesOrder(order.Frequency),
+	)
+	o = gnf.Find("", txt)
+	assert.True(o.WithUniqueNames)
+	assert.True(o.WithAggregatedNames)
+	assert.Equal("frequency", o.UniqueNamesOrder)
+	type name struct {
+		name  string
+		count int
+	}
+	var counts []name
+	for _, v := range o.Names {
+		counts = append(counts, name{v.Name, v.Count})
+		assert.Equal(v.Count, len(v.Occurrences))
+		assert.Equal(v.OffsetStart, v.Occurrences[0].OffsetStart)
+		for _, occ := range v.Occurrences {
+			assert.Contains(v.VerbatimVariants,
+				strings.TrimRight(txt[occ.OffsetStart:occ.OffsetEnd], ".,"))
+		}
+	}
+	assert.Equal([]name{
+		{"Pardosa moesta", 3}, {"Bubo bubo", 2}, {"Pomatomus saltator", 1},
+	}, counts)
+	pardosa := o.Names[0]
+	assert.Equal([]string{"Pardosa moesta,", "Pardosa moesta", "PARDOSA MOESTA"},
+		pardosa.VerbatimVariants)
+	assert.Equal(2, pardosa.Cardinality)
+	assert.Equal(
+		"Pardosa moesta",
+		txt[pardosa.Occurrences[1].OffsetStart:pardosa.Occurrences[1].OffsetEnd],
+	)
+
+	gnf = genFinder(t,
+		config.OptWithAggregatedNames(true),
+		config.OptWithAllCaps(true),
+		config.OptUniqueNamesOrder(order.Appearance),
+	)
+	o = gnf.Find("", txt)
+	names = names[:0]
+	for _, v := range o.Names {
+		names = append(names, v.Name)
+	}
+	assert.Equal([]string{"Pomatomus saltator", "Bubo bubo", "Pardosa moesta"},
+		names)
+
+	res := o.Format(gnfmt.CSV)
+	assert.Contains(res, ",Hybrid,Count\n")
+}
+
+func TestLinePositions(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg, input string
+		opts       []config.Option
+		lineStart  int
+		colStart   int
+		lineEnd    int
+		colEnd     int
+	}{
+		{"first line", "Hello Pardosa moesta", nil, 1, 7, 1, 21},
+		{"third line", "Hello\n\nЭто Pardosa moesta", nil, 3, 5, 3, 19},
+		{"two lines", "Hello Pardosa\nmoesta", nil, 1, 7, 2, 7},
+		{"bytes", "Это\nЭто Pardosa moesta",
+			[]config.Option{config.OptWithPositonInBytes(true)}, 2, 5, 2, 19},
+		{"markup", "<p>Hello</p>\n<p><i>Pardosa moesta</i></p>",
+			[]config.Option{config.OptMarkup(markup.HTML)}, 2, 7, 2, 21},
+	}
+
+	for _, v := range tests {
+		opts := append([]config.Option{config.OptWithLinePositions(true)},
+			v.opts...)
+		gnf := genFinder(t, opts...)
+		o := gnf.Find("", v.input)
+		assert.True(o.WithLinePositions, v.msg)
+		assert.Equal(1, len(o.Names), v.msg)
+		name := o.Names[0]
+		assert.Equal(v.lineStart, name.LineStart, v.msg)
+		assert.Equal(v.colStart, name.ColStart, v.msg)
+		assert.Equal(v.lineEnd, name.LineEnd, v.msg)
+		assert.Equal(v.colEnd, name.ColEnd, v.msg)
+	}
+
+	gnf := genFinder(t, config.OptWithLinePositions(true))
+	o := gnf.Find("", "Hello\nPardosa moesta")
+	csv := strings.Split(o.Format(gnfmt.CSV), "\n")
+	assert.True(strings.HasPrefix(csv[0],
+		"Index,Verbatim,Name,Start,End,LineStart,ColStart,LineEnd,ColEnd,"))
+	assert.True(strings.HasPrefix(csv[1],
+		"0,Pardosa moesta,Pardosa moesta,6,20,2,1,2,15,"))
+
+	gnf = genFinder(t)
+	o = gnf.Find("", "Hello\nPardosa moesta")
+	assert.Equal(0, o.Names[0].LineStart)
+	csv = strings.Split(o.Format(gnfmt.CSV), "\n")
+	assert.False(strings.Contains(csv[0], "LineStart"))
+}
+
+func TestFormFeedPages(t *testing.T) {
+	assert := assert.New(t)
+	gnf := genFinder(t)
+	txt := "Spiders\n\fPardosa moesta Banks\fand\n\fBubo bubo"
+	o := gnf.Find("", txt)
+	assert.Equal(4, o.PagesNum)
+	assert.Equal(2, len(o.Names))
+	assert.Equal("Pardosa moesta", o.Names[0].Verbatim)
+	assert.Equal(2, o.Names[0].Page)
+	assert.Equal("Bubo bubo", o.Names[1].Verbatim)
+	assert.Equal(4, o.Names[1].Page)
+
+	o = gnf.Find("", "Pardosa moesta")
+	assert.Equal(0, o.PagesNum)
+	assert.Equal(0, o.Names[0].Page)
+}
+
 func TestAmbiguousGenera(t *testing.T) {
 	assert := assert.New(t)
 	gnf := genFinder(t)
@@ -14,6 +14,12 @@ type GNfinder interface {
 	// that contains the `text` (if given).
 	Find(file, text string) output.Output
 
+	// FindWithLayout detects names in a `text` extracted from a paged
+	// document, for example a PDF file. The `layout` provides pages and
+	// positions of characters, found names get page numbers and bounding
+	// boxes.
+	FindWithLayout(file, text string, layout output.Layout) output.Output
+
 	// GetConfig provides all public Config fields.
 	GetConfig() config.Config
 
@@ -5,6 +5,8 @@ import (
 	"embed"
 	"encoding/csv"
 	"io"
+	"unicode"
+	"unicode/utf8"
 )
 
 //go:embed data
@@ -16,7 +18,8 @@ type DictionaryType int
 func (d DictionaryType) String() string {
 	types := [...]string{"notSet", "inGenus", "inAmbigGenus", "inAmbigGenusSp",
 		"inUninomial", "inAmbigUninomial", "notInUninomial", "inSpecies",
-		"inAmbigSpecies", "notInSpecies", "commonWords", "rank", "notInDictionary"}
+		"inAmbigSpecies", "notInSpecies", "commonWords", "rank", "notInDictionary",
+		"fuzzyInGenus", "fuzzyInUninomial", "fuzzyInSpecies"}
 	return types[d]
 }
 
@@ -35,6 +38,9 @@ const (
 	CommonWords
 	Rank
 	NotInDictionary
+	FuzzyInGenus
+	FuzzyInUninomial
+	FuzzyInSpecies
 )
 
 // Dictionary contains dictionaries used for detecting scientific names
@@ -111,6 +117,29 @@ func LoadDictionary() (*Dictionary, error) {
 	return d, nil
 }
 
+// Known checks if a word is a genus, an uninomial or a specific epithet
+// from the dictionaries. Capitalized words are checked against genera and
+// uninomials, other words against epithets.
+func (d *Dictionary) Known(word string) bool {
+	if word == "" {
+		return false
+	}
+	var dicts []map[string]struct{}
+	if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+		dicts = []map[string]struct{}{
+			d.InGenera, d.InAmbigGenera, d.InUninomials, d.InAmbigUninomials,
+		}
+	} else {
+		dicts = []map[string]struct{}{d.InSpecies, d.InAmbigSpecies}
+	}
+	for _, v := range dicts {
+		if _, ok := v[word]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func readData(path string) (map[string]struct{}, error) {
 	res := make(map[string]struct{})
 	f, err := data.Open(path)
@@ -36,3 +36,42 @@ func TestInGenera(t *testing.T) {
 	_, ok := dictionary.InGenera["Plantago"]
 	assert.True(ok)
 }
+
+func TestFuzzy(t *testing.T) {
+	assert := assert.New(t)
+	dictionary, err := dict.LoadDictionary()
+	assert.Nil(err)
+	tests := []struct {
+		msg, word, res string
+		ok             bool
+	}{
+		{"deletion", "Pardoa", "Pardosa", true},
+		{"insertion", "Pardossa", "Pardosa", true},
+		{"substitution", "Pardoza", "Pardosa", true},
+		{"transposition", "Pradosa", "Pardosa", true},
+		{"first letter", "Bardosa", "", false},
+		{"two edits", "Pardzza", "", false},
+		{"too short", "Homa", "", false},
+	}
+	for _, v := range tests {
+		res, ok := dictionary.FuzzyGenus(v.word)
+		assert.Equal(v.res, res, v.msg)
+		assert.Equal(v.ok, ok, v.msg)
+	}
+
+	res, ok := dictionary.FuzzySpecies("moestta")
+	assert.True(ok)
+	assert.Equal("moesta", res)
+}
+
+func TestKnown(t *testing.T) {
+	assert := assert.New(t)
+	dictionary, err := dict.LoadDictionary()
+	assert.Nil(err)
+	assert.True(dictionary.Known("Pardosa"))
+	assert.True(dictionary.Known("moesta"))
+	assert.True(dictionary.Known("Aacrobata"))
+	assert.False(dictionary.Known("Moesta"))
+	assert.False(dictionary.Known("pardosa"))
+	assert.False(dictionary.Known(""))
+}
@@ -0,0 +1,106 @@
+package dict
+
+import "strings"
+
+// fuzzyMinLen is the minimal length of a word for fuzzy matching. Shorter
+// words have too many dictionary entries within one edit.
+const fuzzyMinLen = 5
+
+const alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// FuzzyGenus returns a genus from the dictionary that is one edit away
+// from the word. The first letter of the word is not changed. It returns
+// false if there is no such genus, or if there are several of them.
+func (d *Dictionary) FuzzyGenus(word string) (string, bool) {
+	return fuzzyCapitalized(word, d.InGenera)
+}
+
+// FuzzyUninomial returns an uninomial from the dictionary that is one edit
+// away from the word. The first letter of the word is not changed. It
+// returns false if there is no such uninomial, or if there are several of
+// them.
+func (d *Dictionary) FuzzyUninomial(word string) (string, bool) {
+	return fuzzyCapitalized(word, d.InUninomials)
+}
+
+// FuzzySpecies returns a specific epithet from the dictionary that is one
+// edit away from the word. It returns false if there is no such epithet,
+// or if there are several of them.
+func (d *Dictionary) FuzzySpecies(word string) (string, bool) {
+	if len(word) < fuzzyMinLen || !isLowASCII(word) {
+		return "", false
+	}
+	return fuzzyMatch("", word, d.InSpecies)
+}
+
+func fuzzyCapitalized(word string, dict map[string]struct{}) (string, bool) {
+	if len(word) < fuzzyMinLen || word[0] < 'A' || word[0] > 'Z' ||
+		!isLowASCII(word[1:]) {
+		return "", false
+	}
+	return fuzzyMatch(word[:1], word[1:], dict)
+}
+
+// isLowASCII checks if the word consists of low-case ASCII letters.
+func isLowASCII(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyMatch generates all variants of the word that are one deletion,
+// transposition, substitution or insertion away from it, and looks them up
+// in the dictionary. The prefix is not changed.
+func fuzzyMatch(
+	prefix, word string,
+	dict map[string]struct{},
+) (string, bool) {
+	var res string
+	check := func(v string) bool {
+		v = prefix + v
+		if _, ok := dict[v]; !ok || v == res {
+			return true
+		}
+		if res != "" {
+			// ambiguous match
+			return false
+		}
+		res = v
+		return true
+	}
+
+	l := len(word)
+	var sb strings.Builder
+	variant := func(parts ...string) string {
+		sb.Reset()
+		for _, p := range parts {
+			sb.WriteString(p)
+		}
+		return sb.String()
+	}
+	for i := 0; i <= l; i++ {
+		if i < l && !check(variant(word[:i], word[i+1:])) {
+			return "", false
+		}
+		if i < l-1 {
+			t := variant(word[:i], word[i+1:i+2], word[i:i+1], word[i+2:])
+			if !check(t) {
+				return "", false
+			}
+		}
+		for j := 0; j < len(alphabet); j++ {
+			c := alphabet[j : j+1]
+			if i < l && c != word[i:i+1] &&
+				!check(variant(word[:i], c, word[i+1:])) {
+				return "", false
+			}
+			if !check(variant(word[:i], c, word[i:])) {
+				return "", false
+			}
+		}
+	}
+	return res, res != ""
+}
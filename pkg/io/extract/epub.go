@@ -0,0 +1,89 @@
+package extract
+
+import (
+	"encoding/xml"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// container is the META-INF/container.xml file of EPUB.
+type container struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// opfPackage is the package document of EPUB. It lists the files of the
+// book and their reading order.
+type opfPackage struct {
+	Items []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Itemrefs []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// epubText extracts text of HTML documents of EPUB in their reading order.
+func epubText(a *archive) (string, error) {
+	docs, err := epubDocs(a)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, v := range docs {
+		data, err := a.file(v)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(htmlText(data))
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+// epubDocs returns paths of HTML documents from the spine of EPUB.
+func epubDocs(a *archive) ([]string, error) {
+	data, err := a.file("META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	var c container
+	if err = xml.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if len(c.Rootfiles) == 0 {
+		return nil, errNoRootfile
+	}
+	opf := c.Rootfiles[0].FullPath
+	if data, err = a.file(opf); err != nil {
+		return nil, err
+	}
+	var p opfPackage
+	if err = xml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+
+	hrefs := make(map[string]string)
+	for _, v := range p.Items {
+		if strings.Contains(v.MediaType, "html") {
+			hrefs[v.ID] = v.Href
+		}
+	}
+	dir := path.Dir(opf)
+	var res []string
+	for _, v := range p.Itemrefs {
+		href, ok := hrefs[v.IDRef]
+		if !ok {
+			continue
+		}
+		if h, err := url.PathUnescape(href); err == nil {
+			href = h
+		}
+		res = append(res, path.Join(dir, href))
+	}
+	return res, nil
+}
@@ -0,0 +1,269 @@
+// Package extract converts common document formats into UTF-8 encoded
+// text locally. It supports plain texts, HTML, XHTML, DOCX, ODT and EPUB
+// files. PDF files are converted together with their page layout. Other
+// formats require a remote Apache Tika service.
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gnames/gnfinder/pkg/ent/output"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ErrUnsupported is returned for formats that cannot be converted locally.
+var ErrUnsupported = errors.New("unsupported document format")
+
+// ErrTooLarge is returned for downloaded or unpacked files that exceed
+// the maximal size.
+var ErrTooLarge = errors.New("document is too large")
+
+var errNoRootfile = errors.New("no rootfile in EPUB container")
+
+// maxMimetypeSize limits the mimetype file of ODT and EPUB archives.
+const maxMimetypeSize = 1024
+
+// Format is a document format recognized by its signature.
+type Format int
+
+// Formats that can be converted to text locally.
+const (
+	Unknown Format = iota
+	PlainText
+	HTML
+	DOCX
+	ODT
+	EPUB
+	PDF
+)
+
+var formatStrings = [...]string{
+	"", "text", "html", "docx", "odt", "epub", "pdf",
+}
+
+// String representation of a Format.
+func (f Format) String() string {
+	return formatStrings[f]
+}
+
+// Document is a text extracted from a file.
+type Document struct {
+	// Text is UTF-8 encoded text of the document.
+	Text string
+
+	// Format of the original file.
+	Format Format
+
+	// Layout contains pages of the document and positions of characters
+	// on them. Only PDF documents have a layout.
+	Layout output.Layout
+}
+
+// Detect finds the format of a document by its signature. Zip-based
+// formats are recognized by their content.
+func Detect(data []byte) Format {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return zipFormat(data)
+	}
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return PDF
+	}
+	if isHTML(data) {
+		return HTML
+	}
+	if utf8.Valid(data) && bytes.IndexByte(data, 0) < 0 {
+		return PlainText
+	}
+	return Unknown
+}
+
+// Text converts a document into UTF-8 encoded text. It returns
+// ErrUnsupported if the format of the document is not recognized.
+// Files unpacked from archives cannot exceed maxSize bytes together.
+func Text(data []byte, maxSize int64) (string, error) {
+	doc, err := Extract(data, maxSize)
+	return doc.Text, err
+}
+
+// Extract converts a document into UTF-8 encoded text. For PDF files it
+// also returns the layout of the text on pages. It returns ErrUnsupported
+// if the format of the document is not recognized, and ErrNoTextLayer for
+// PDF files without text. Files unpacked from DOCX, ODT and EPUB archives
+// cannot exceed maxSize bytes together, otherwise ErrTooLarge is returned.
+func Extract(data []byte, maxSize int64) (Document, error) {
+	var err error
+	var res string
+	f := Detect(data)
+	switch f {
+	case PlainText:
+		res = strings.TrimPrefix(string(data), "\ufeff")
+	case HTML:
+		res = htmlText(data)
+	case DOCX:
+		res, err = zipText(data, maxSize, docxText)
+	case ODT:
+		res, err = zipText(data, maxSize, odtText)
+	case EPUB:
+		res, err = zipText(data, maxSize, epubText)
+	case PDF:
+		doc, err := pdfText(data)
+		if err != nil && !errors.Is(err, ErrNoTextLayer) {
+			err = fmt.Errorf("cannot extract text: %w", err)
+		}
+		return doc, err
+	default:
+		return Document{}, ErrUnsupported
+	}
+	if err != nil {
+		return Document{}, fmt.Errorf("cannot extract text: %w", err)
+	}
+	return Document{Text: res, Format: f}, nil
+}
+
+// Download gets the content of a URL. It returns ErrTooLarge if the content
+// is larger than maxSize bytes.
+func Download(url, userAgent string, maxSize int64) ([]byte, error) {
+	client := &http.Client{Timeout: 3 * time.Minute}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,text/plain,application/xhtml+xml,*/*")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot download %s: %s", url, resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return nil, tooLarge(maxSize)
+	}
+	return ReadAll(resp.Body, maxSize)
+}
+
+// ReadAll reads from r until EOF. It returns ErrTooLarge if there are more
+// than maxSize bytes to read.
+func ReadAll(r io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, tooLarge(maxSize)
+	}
+	return data, nil
+}
+
+func tooLarge(maxSize int64) error {
+	return fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxSize)
+}
+
+// isHTML checks if a document starts as HTML or XHTML.
+func isHTML(data []byte) bool {
+	limit := 1024
+	head := bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	head = bytes.TrimLeft(head[:min(len(head), limit)], " \t\r\n")
+	if len(head) == 0 || head[0] != '<' {
+		return false
+	}
+	head = bytes.ToLower(head)
+	return bytes.Contains(head, []byte("<!doctype html")) ||
+		bytes.Contains(head, []byte("<html"))
+}
+
+// utf8Text returns a UTF-8 string. Texts that are not valid UTF-8 are
+// decoded as Windows-1252, the most common encoding of old web pages.
+func utf8Text(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	res, err := charmap.Windows1252.NewDecoder().Bytes(data)
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "�")
+	}
+	return string(res)
+}
+
+// zipFormat recognizes DOCX, ODT and EPUB files.
+func zipFormat(data []byte) Format {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Unknown
+	}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			return DOCX
+		case "mimetype":
+			mt, err := readZipFile(f, maxMimetypeSize)
+			if err != nil {
+				return Unknown
+			}
+			switch strings.TrimSpace(string(mt)) {
+			case "application/vnd.oasis.opendocument.text":
+				return ODT
+			case "application/epub+zip":
+				return EPUB
+			}
+		}
+	}
+	return Unknown
+}
+
+// archive is a zip archive of a document. It keeps track of the number
+// of bytes that can still be unpacked from it.
+type archive struct {
+	zr   *zip.Reader
+	left int64
+}
+
+func zipText(
+	data []byte,
+	maxSize int64,
+	text func(*archive) (string, error),
+) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	return text(&archive{zr: zr, left: maxSize})
+}
+
+// file returns the content of a file from the archive.
+func (a *archive) file(name string) ([]byte, error) {
+	for _, f := range a.zr.File {
+		if f.Name == name {
+			data, err := readZipFile(f, a.left)
+			a.left -= int64(len(data))
+			return data, err
+		}
+	}
+	return nil, fmt.Errorf("file %s not found", name)
+}
+
+// readZipFile returns the content of a zip file. The declared size of the
+// file is checked first, and reading stops after maxSize bytes, so zip
+// bombs with a false size are rejected as well.
+func readZipFile(f *zip.File, maxSize int64) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(maxSize) {
+		return nil, tooLarge(maxSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ReadAll(rc, maxSize)
+}
@@ -0,0 +1,217 @@
+package extract_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gnames/gnfinder/pkg/ent/output"
+	"github.com/gnames/gnfinder/pkg/io/extract"
+	"github.com/stretchr/testify/assert"
+)
+
+// maxSize limits sizes of unpacked files in tests.
+const maxSize = 1 << 20
+
+func zipDoc(t *testing.T, files [][2]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, v := range files {
+		w, err := zw.Create(v[0])
+		assert.Nil(t, err)
+		_, err = w.Write([]byte(v[1]))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, zw.Close())
+	return buf.Bytes()
+}
+
+const docx = `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body><w:p><w:r><w:t>The </w:t></w:r><w:r><w:rPr><w:i/></w:rPr>
+<w:t>Pardosa moesta</w:t></w:r><w:r><w:tab/><w:t>Banks</w:t></w:r></w:p>
+<w:p><w:r><w:t>Bubo &amp; bubo</w:t></w:r></w:p></w:body></w:document>`
+
+const odt = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content
+ xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+ xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:text><text:h>Spiders</text:h>
+<text:p>The <text:span>Pardosa moesta</text:span><text:s text:c="2"/>Banks</text:p>
+</office:text></office:body></office:document-content>`
+
+const container = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles><rootfile full-path="OEBPS/content.opf"
+ media-type="application/oebps-package+xml"/></rootfiles></container>`
+
+const opf = `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+<manifest>
+<item id="c2" href="ch%202.xhtml" media-type="application/xhtml+xml"/>
+<item id="c1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+<item id="css" href="style.css" media-type="text/css"/>
+</manifest>
+<spine><itemref idref="c1"/><itemref idref="c2"/></spine></package>`
+
+func TestText(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		msg    string
+		data   []byte
+		format extract.Format
+		text   string
+	}{
+		{"plain", []byte("\xef\xbb\xbfPardosa moesta"), extract.PlainText,
+			"Pardosa moesta"},
+		{"html", []byte("<!DOCTYPE html><html><body><p><i>Pardosa</i>" +
+			" moesta&nbsp;Banks</p></body></html>"), extract.HTML,
+			"\n\nPardosa moesta\u00a0Banks\n\n"},
+		{"html latin1", []byte("<html><p>Pardosa mo\xe9sta</p></html>"),
+			extract.HTML, "\nPardosa moésta\n"},
+		{"docx", zipDoc(t, [][2]string{
+			{"[Content_Types].xml", "<Types/>"},
+			{"word/document.xml", docx},
+		}), extract.DOCX, "The Pardosa moesta\tBanks\nBubo & bubo\n"},
+		{"odt", zipDoc(t, [][2]string{
+			{"mimetype", "application/vnd.oasis.opendocument.text"},
+			{"content.xml", odt},
+		}), extract.ODT, "Spiders\nThe Pardosa moesta  Banks\n"},
+		{"epub", zipDoc(t, [][2]string{
+			{"mimetype", "application/epub+zip"},
+			{"META-INF/container.xml", container},
+			{"OEBPS/content.opf", opf},
+			{"OEBPS/ch1.xhtml", "<html><body><p>Pardosa moesta</p></body></html>"},
+			{"OEBPS/ch 2.xhtml", "<html><body><p>Bubo bubo</p></body></html>"},
+		}), extract.EPUB,
+			"\n\nPardosa moesta\n\n\n\n\nBubo bubo\n\n\n"},
+	}
+	for _, v := range tests {
+		assert.Equal(v.format, extract.Detect(v.data), v.msg)
+		res, err := extract.Text(v.data, maxSize)
+		assert.Nil(err, v.msg)
+		assert.Equal(v.text, res, v.msg)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	assert := assert.New(t)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	assert.Equal(extract.Unknown, extract.Detect(png))
+	_, err := extract.Text(png, maxSize)
+	assert.ErrorIs(err, extract.ErrUnsupported)
+
+	xlsx := zipDoc(t, [][2]string{{"xl/workbook.xml", "<workbook/>"}})
+	_, err = extract.Text(xlsx, maxSize)
+	assert.ErrorIs(err, extract.ErrUnsupported)
+}
+
+func TestTooLarge(t *testing.T) {
+	assert := assert.New(t)
+	doc := strings.Replace(docx, "Bubo &amp; bubo",
+		strings.Repeat("Bubo bubo ", 100), 1)
+	data := zipDoc(t, [][2]string{{"word/document.xml", doc}})
+	_, err := extract.Text(data, 1000)
+	assert.ErrorIs(err, extract.ErrTooLarge)
+	res, err := extract.Text(data, 10000)
+	assert.Nil(err)
+	assert.Contains(res, "Pardosa moesta")
+
+	ch := "<html><body><p>" + strings.Repeat("Bubo bubo ", 60) +
+		"</p></body></html>"
+	epub := zipDoc(t, [][2]string{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", container},
+		{"OEBPS/content.opf", opf},
+		{"OEBPS/ch1.xhtml", ch},
+		{"OEBPS/ch 2.xhtml", ch},
+	})
+	_, err = extract.Text(epub, 1500)
+	assert.ErrorIs(err, extract.ErrTooLarge, "files are limited together")
+
+	res2, err := extract.ReadAll(strings.NewReader("Bubo bubo"), 9)
+	assert.Nil(err)
+	assert.Equal("Bubo bubo", string(res2))
+	_, err = extract.ReadAll(strings.NewReader("Bubo bubo"), 8)
+	assert.ErrorIs(err, extract.ErrTooLarge)
+}
+
+// pdfDoc creates a PDF file with a page for every content stream.
+func pdfDoc(contents ...string) []byte {
+	widths := strings.TrimSpace(strings.Repeat("500 ", 95))
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica " +
+			"/FirstChar 32 /LastChar 126 /Widths [" + widths + "] >>",
+	}
+	var kids []string
+	for _, c := range contents {
+		n := len(objs)
+		kids = append(kids, fmt.Sprintf("%d 0 R", n+1))
+		objs = append(objs,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R "+
+				"/MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> "+
+				"/Contents %d 0 R >>", n+2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(c)+1, c),
+		)
+	}
+	objs[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(kids))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objs))
+	for i, v := range objs {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, v)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, v := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", v)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objs)+1, xref)
+	return buf.Bytes()
+}
+
+func TestPDF(t *testing.T) {
+	assert := assert.New(t)
+	data := pdfDoc(
+		"BT /F1 10 Tf 72 700 Td (Spiders) Tj ET",
+		"BT /F1 10 Tf 72 700 Td (The Pardosa) Tj 0 -20 Td (moesta Banks) Tj ET",
+	)
+	assert.Equal(extract.PDF, extract.Detect(data))
+	doc, err := extract.Extract(data, maxSize)
+	assert.Nil(err)
+	assert.Equal(extract.PDF, doc.Format)
+	assert.Equal("Spiders\n\fThe Pardosa\nmoesta Banks", doc.Text)
+
+	l := doc.Layout
+	assert.Equal(len([]rune(doc.Text)), len(l.Boxes))
+	assert.Equal([]output.Page{
+		{Number: 1, OffsetStart: 0, OffsetEnd: 7},
+		{Number: 2, OffsetStart: 9, OffsetEnd: 33},
+	}, l.Pages)
+	assert.Equal(output.Box{X0: 72, Y0: 700, X1: 77, Y1: 710}, l.Boxes[0])
+	assert.True(l.Boxes[7].IsEmpty())
+	assert.Equal(output.Box{X0: 72, Y0: 680, X1: 77, Y1: 690}, l.Boxes[21])
+
+	res, err := extract.Text(data, maxSize)
+	assert.Nil(err)
+	assert.Equal(doc.Text, res)
+}
+
+func TestPDFNoText(t *testing.T) {
+	assert := assert.New(t)
+	data := pdfDoc("0 0 m 100 100 l S")
+	_, err := extract.Extract(data, maxSize)
+	assert.ErrorIs(err, extract.ErrNoTextLayer)
+
+	_, err = extract.Extract([]byte("%PDF-1.7\n\x00\x01\x02\xff"), maxSize)
+	assert.NotNil(err)
+	assert.NotErrorIs(err, extract.ErrUnsupported)
+}
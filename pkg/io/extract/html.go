@@ -0,0 +1,10 @@
+package extract
+
+import "github.com/gnames/gnfinder/pkg/ent/markup"
+
+// htmlText removes tags from HTML and XHTML documents, and substitutes
+// character entities.
+func htmlText(data []byte) string {
+	mt := markup.Parse([]rune(utf8Text(data)), markup.HTML)
+	return string(mt.Text)
+}
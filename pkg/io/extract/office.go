@@ -0,0 +1,126 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	docxNS = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	odtNS  = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+// docxText extracts text of paragraphs from the main document of a DOCX
+// file.
+func docxText(a *archive) (string, error) {
+	data, err := a.file("word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	var inText bool
+	err = walkXML(data, func(t xml.Token) {
+		switch e := t.(type) {
+		case xml.StartElement:
+			if e.Name.Space != docxNS {
+				return
+			}
+			switch e.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte('\t')
+			case "br", "cr":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			if e.Name.Space != docxNS {
+				return
+			}
+			switch e.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(e)
+			}
+		}
+	})
+	return sb.String(), err
+}
+
+// odtText extracts text of paragraphs and headings from the content of an
+// ODT file.
+func odtText(a *archive) (string, error) {
+	data, err := a.file("content.xml")
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	var depth int
+	err = walkXML(data, func(t xml.Token) {
+		switch e := t.(type) {
+		case xml.StartElement:
+			if e.Name.Space != odtNS {
+				return
+			}
+			switch e.Name.Local {
+			case "p", "h":
+				depth++
+			case "s":
+				sb.WriteString(strings.Repeat(" ", spacesNum(e)))
+			case "tab":
+				sb.WriteByte('\t')
+			case "line-break":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			if e.Name.Space != odtNS {
+				return
+			}
+			if e.Name.Local == "p" || e.Name.Local == "h" {
+				depth--
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if depth > 0 {
+				sb.Write(e)
+			}
+		}
+	})
+	return sb.String(), err
+}
+
+// spacesNum returns the number of spaces of "text:s" element.
+func spacesNum(e xml.StartElement) int {
+	for _, a := range e.Attr {
+		if a.Name.Local == "c" {
+			if n, err := strconv.Atoi(a.Value); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 1
+}
+
+// walkXML sends every token of an XML document to a function.
+func walkXML(data []byte, f func(xml.Token)) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f(t)
+	}
+}
@@ -0,0 +1,124 @@
+package extract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gnames/gnfinder/pkg/ent/output"
+	"github.com/ledongthuc/pdf"
+)
+
+// ErrNoTextLayer is returned for PDF files that contain only images of
+// pages, for example scanned documents without OCR.
+var ErrNoTextLayer = errors.New(
+	"PDF has no text layer, it might be a scanned document",
+)
+
+// pdfText extracts the text of a PDF file page by page. Every character
+// of the text gets its position on the page, pages are separated by a new
+// line and a form feed character.
+func pdfText(data []byte) (doc Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot parse PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return doc, err
+	}
+
+	b := &pdfBuilder{}
+	for i := 1; i <= r.NumPage(); i++ {
+		p := r.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		b.addPage(i, p.Content().Text)
+	}
+
+	if strings.TrimSpace(string(b.text)) == "" {
+		return doc, ErrNoTextLayer
+	}
+
+	doc = Document{
+		Text:   string(b.text),
+		Format: PDF,
+		Layout: output.Layout{Pages: b.pages, Boxes: b.boxes},
+	}
+	return doc, nil
+}
+
+// pdfBuilder collects characters of pages together with their positions.
+type pdfBuilder struct {
+	text  []rune
+	boxes []output.Box
+	pages []output.Page
+}
+
+// addPage adds glyphs of a page to the text. Glyphs that are far from
+// each other horizontally are separated by a space, glyphs on different
+// lines are separated by a new line.
+func (b *pdfBuilder) addPage(num int, glyphs []pdf.Text) {
+	if len(b.text) > 0 {
+		b.add('\n', output.Box{})
+		b.add('\f', output.Box{})
+	}
+	start := len(b.text)
+
+	var prev pdf.Text
+	for i, g := range glyphs {
+		if g.S == "" {
+			continue
+		}
+		if i > 0 {
+			b.addSeparator(prev, g)
+		}
+		n := utf8.RuneCountInString(g.S)
+		w := g.W / float64(n)
+		var j int
+		for _, r := range g.S {
+			x := g.X + w*float64(j)
+			box := output.Box{X0: x, Y0: g.Y, X1: x + w, Y1: g.Y + g.FontSize}
+			b.add(r, box)
+			j++
+		}
+		prev = g
+	}
+
+	b.pages = append(b.pages, output.Page{
+		Number:      num,
+		OffsetStart: start,
+		OffsetEnd:   len(b.text),
+	})
+}
+
+func (b *pdfBuilder) addSeparator(prev, g pdf.Text) {
+	size := math.Max(prev.FontSize, g.FontSize)
+	if math.Abs(prev.Y-g.Y) > size/2 {
+		b.add('\n', output.Box{})
+		return
+	}
+	if b.endsWithSpace() || strings.HasPrefix(g.S, " ") {
+		return
+	}
+	gap := g.X - (prev.X + prev.W)
+	if gap > size*0.15 || g.X < prev.X {
+		b.add(' ', output.Box{})
+	}
+}
+
+func (b *pdfBuilder) endsWithSpace() bool {
+	l := len(b.text)
+	return l > 0 && (b.text[l-1] == ' ' || b.text[l-1] == '\n')
+}
+
+func (b *pdfBuilder) add(r rune, box output.Box) {
+	b.text = append(b.text, r)
+	b.boxes = append(b.boxes, box)
+}
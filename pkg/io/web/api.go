@@ -10,13 +10,20 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gnames/gndoc"
 	gnfinder "github.com/gnames/gnfinder/pkg"
 	"github.com/gnames/gnfinder/pkg/config"
 	"github.com/gnames/gnfinder/pkg/ent/api"
+	"github.com/gnames/gnfinder/pkg/ent/checklist"
+	"github.com/gnames/gnfinder/pkg/ent/graph"
 	"github.com/gnames/gnfinder/pkg/ent/lang"
+	"github.com/gnames/gnfinder/pkg/ent/markup"
+	"github.com/gnames/gnfinder/pkg/ent/offset"
+	"github.com/gnames/gnfinder/pkg/ent/order"
 	"github.com/gnames/gnfinder/pkg/ent/output"
+	"github.com/gnames/gnfinder/pkg/ent/section"
+	"github.com/gnames/gnfinder/pkg/ent/segment"
 	"github.com/gnames/gnfinder/pkg/ent/verifier"
+	"github.com/gnames/gnfinder/pkg/io/extract"
 	"github.com/gnames/gnfmt"
 	"github.com/labstack/echo/v4"
 )
@@ -77,6 +84,22 @@ func paramsFindGET(c echo.Context) api.FinderParams {
 	if num, err := strconv.Atoi(c.QueryParam("words_around")); err == nil {
 		wordsAround = num
 	}
+	var checklistColumn int
+	if num, err := strconv.Atoi(c.QueryParam("checklist_column")); err == nil {
+		checklistColumn = num
+	}
+	var contextSize int
+	if num, err := strconv.Atoi(c.QueryParam("context_size")); err == nil {
+		contextSize = num
+	}
+	var graphTokens int
+	if num, err := strconv.Atoi(c.QueryParam("graph_tokens")); err == nil {
+		graphTokens = num
+	}
+	var excludeSections []string
+	if s := c.QueryParam("exclude_sections"); s != "" {
+		excludeSections = strings.Split(s, ",")
+	}
 	var sources []int
 	elements := strings.Split(c.QueryParam("sources"), ",")
 	for _, v := range elements {
@@ -87,20 +110,34 @@ func paramsFindGET(c echo.Context) api.FinderParams {
 	}
 
 	params := api.FinderParams{
-		URL:            textURL,
-		Text:           text,
-		Format:         c.QueryParam("format"),
-		Language:       c.QueryParam("language"),
-		BytesOffset:    c.QueryParam("bytes_offset") == "true",
-		ReturnContent:  c.QueryParam("return_content") == "true",
-		UniqueNames:    c.QueryParam("unique_names") == "true",
-		AmbiguousNames: c.QueryParam("ambiguous_names") == "true",
-		NoBayes:        c.QueryParam("no_bayes") == "true",
-		OddsDetails:    c.QueryParam("odds_details") == "true",
-		WordsAround:    wordsAround,
-		Verification:   c.QueryParam("verification") == "true",
-		Sources:        sources,
-		AllMatches:     c.QueryParam("all_matches") == "true",
+		URL:              textURL,
+		Text:             text,
+		Format:           c.QueryParam("format"),
+		Language:         c.QueryParam("language"),
+		BytesOffset:      c.QueryParam("bytes_offset") == "true",
+		OffsetUnit:       c.QueryParam("offset_unit"),
+		Markup:           c.QueryParam("markup"),
+		LinePositions:    c.QueryParam("line_positions") == "true",
+		Checklist:        c.QueryParam("checklist"),
+		ChecklistColumn:  checklistColumn,
+		Context:          c.QueryParam("context"),
+		ContextSize:      contextSize,
+		ExcludeSections:  excludeSections,
+		Graph:            c.QueryParam("graph"),
+		GraphTokens:      graphTokens,
+		Hierarchy:        c.QueryParam("hierarchy") == "true",
+		Tables:           c.QueryParam("tables") == "true",
+		ReturnContent:    c.QueryParam("return_content") == "true",
+		UniqueNames:      c.QueryParam("unique_names") == "true",
+		AggregatedNames:  c.QueryParam("aggregated_names") == "true",
+		UniqueNamesOrder: c.QueryParam("unique_names_order"),
+		AmbiguousNames:   c.QueryParam("ambiguous_names") == "true",
+		NoBayes:          c.QueryParam("no_bayes") == "true",
+		OddsDetails:      c.QueryParam("odds_details") == "true",
+		WordsAround:      wordsAround,
+		Verification:     c.QueryParam("verification") == "true",
+		Sources:          sources,
+		AllMatches:       c.QueryParam("all_matches") == "true",
 	}
 
 	if len(params.Sources) > 0 || params.AllMatches {
@@ -138,21 +175,18 @@ func findApiPOST(gnf gnfinder.GNfinder) func(echo.Context) error {
 
 func finder(c echo.Context, gnf gnfinder.GNfinder, params api.FinderParams, chErr chan<- error) {
 	var err error
-	var text, filename string
+	var doc extract.Document
+	var filename string
 	var txtExtr float32
 	var out output.Output
 	var format gnfmt.Format
 	var opts []config.Option
 
-	text, filename, txtExtr, err = getText(
-		c,
-		params,
-		gnf.GetConfig().TikaURL,
-	)
-
 	opts, format = getOptsAPI(params)
 	gnf = gnf.ChangeConfig(opts...)
-	out = gnf.Find(filename, text)
+
+	doc, filename, txtExtr, err = getText(c, params, gnf.GetConfig())
+	out = gnf.FindWithLayout(filename, doc.Text, doc.Layout)
 	out.TextExtractionSec = txtExtr
 	cfg := gnf.GetConfig()
 	if cfg.WithVerification {
@@ -188,12 +222,23 @@ func getOptsAPI(params api.FinderParams) ([]config.Option, gnfmt.Format) {
 		config.OptWithBayesOddsDetails(params.OddsDetails),
 		config.OptFormat(format),
 		config.OptWithBayes(!params.NoBayes),
-		config.OptWithPositonInBytes(params.BytesOffset),
+		config.OptOffsetUnit(getOffsetUnit(params)),
+		config.OptMarkup(getMarkup(params.Markup)),
+		config.OptWithLinePositions(params.LinePositions),
+		config.OptChecklist(getChecklist(params.Checklist)),
+		config.OptChecklistColumn(params.ChecklistColumn),
+		config.OptExcludeSections(getSections(params.ExcludeSections)),
+		config.OptWithHierarchy(params.Hierarchy),
+		config.OptWithTables(params.Tables),
+		config.OptGraphWindow(getGraphWindow(params.Graph)),
+		config.OptContextMode(getContextMode(params.Context)),
 		config.OptLanguage(getLanguage(params.Language)),
 		config.OptDataSources(params.Sources),
 		config.OptIncludeInputText(params.ReturnContent),
 		config.OptWithAllMatches(params.AllMatches),
 		config.OptWithAmbiguousNames(params.AmbiguousNames),
+		config.OptWithAggregatedNames(params.AggregatedNames),
+		config.OptUniqueNamesOrder(getOrder(params.UniqueNamesOrder)),
 		config.OptWithVerification(
 			params.Verification ||
 				len(params.Sources) > 0 ||
@@ -201,6 +246,12 @@ func getOptsAPI(params api.FinderParams) ([]config.Option, gnfmt.Format) {
 		),
 		config.OptTokensAround(params.WordsAround),
 	}
+	if params.ContextSize > 0 {
+		opts = append(opts, config.OptContextSize(params.ContextSize))
+	}
+	if params.GraphTokens > 0 {
+		opts = append(opts, config.OptGraphTokens(params.GraphTokens))
+	}
 	return opts, format
 }
 
@@ -209,6 +260,53 @@ func getLanguage(s string) lang.Language {
 	return l
 }
 
+func getOrder(s string) order.Order {
+	o, _ := order.New(s)
+	return o
+}
+
+func getContextMode(s string) segment.ContextMode {
+	m, _ := segment.NewContextMode(s)
+	return m
+}
+
+func getGraphWindow(s string) graph.Window {
+	w, _ := graph.NewWindow(s)
+	return w
+}
+
+func getMarkup(s string) markup.Markup {
+	m, _ := markup.New(s)
+	return m
+}
+
+func getChecklist(s string) checklist.Mode {
+	m, _ := checklist.New(s)
+	return m
+}
+
+// getSections converts names of sections to sections, unknown names are
+// ignored.
+func getSections(ss []string) []section.Section {
+	var res []section.Section
+	for _, v := range ss {
+		if s, err := section.New(v); err == nil {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// getOffsetUnit returns the unit of offsets. The deprecated BytesOffset
+// parameter is used only if OffsetUnit is not set.
+func getOffsetUnit(params api.FinderParams) offset.Unit {
+	if params.OffsetUnit == "" && params.BytesOffset {
+		return offset.Byte
+	}
+	u, _ := offset.New(params.OffsetUnit)
+	return u
+}
+
 func getContext(c echo.Context) (ctx context.Context, cancel func()) {
 	ctx = c.Request().Context()
 	ctx, cancel = context.WithTimeout(ctx, 1*time.Minute)
@@ -218,22 +316,22 @@ func getContext(c echo.Context) (ctx context.Context, cancel func()) {
 func getText(
 	c echo.Context,
 	params api.FinderParams,
-	tikaURL string,
-) (string, string, float32, error) {
+	cfg config.Config,
+) (extract.Document, string, float32, error) {
 	var err error
-	var txt, filename string
+	var doc extract.Document
+	var filename string
 	var dur float32
 
 	if params.Text != "" {
-		return params.Text, filename, dur, err
+		doc.Text = params.Text
+		return doc, filename, dur, err
 	}
 
 	if params.URL != "" {
-		ua := "gnfinder/" + gnfinder.Version + " (https://github.com/gnames/gnfinder)"
-		d := gndoc.New(tikaURL, gndoc.OptUserAgent(ua))
-		txt, dur, err = d.TextFromURL(params.URL)
-		return txt, filename, dur, err
+		doc, dur, err = textFromURL(params.URL, cfg)
+		return doc, filename, dur, err
 	}
 
-	return textFromFile(c, tikaURL)
+	return textFromFile(c, cfg)
 }
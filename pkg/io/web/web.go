@@ -1,6 +1,9 @@
 package web
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
@@ -8,8 +11,10 @@ import (
 	gnfinder "github.com/gnames/gnfinder/pkg"
 	"github.com/gnames/gnfinder/pkg/config"
 	"github.com/gnames/gnfinder/pkg/ent/api"
+	"github.com/gnames/gnfinder/pkg/ent/markup"
 	"github.com/gnames/gnfinder/pkg/ent/output"
 	"github.com/gnames/gnfinder/pkg/ent/verifier"
+	"github.com/gnames/gnfinder/pkg/io/extract"
 	"github.com/gnames/gnfmt"
 	"github.com/labstack/echo/v4"
 )
@@ -53,7 +58,8 @@ func find(gnf gnfinder.GNfinder) func(echo.Context) error {
 	return func(c echo.Context) error {
 		var err error
 		var params api.FinderParams
-		var txt, filename string
+		var doc extract.Document
+		var filename string
 		var out output.Output
 		var dur Duration
 
@@ -62,25 +68,22 @@ func find(gnf gnfinder.GNfinder) func(echo.Context) error {
 			return err
 		}
 
-		tikaURL := gnf.GetConfig().TikaURL
-
 		opts := getOpts(params)
 		gnf = gnf.ChangeConfig(opts...)
+		cfg := gnf.GetConfig()
 
 		if t := params.Text; t != "" {
-			txt = t
+			doc.Text = t
 		} else if url := params.URL; url != "" {
-			ua := "gnfinder/" + gnfinder.Version + " (https://github.com/gnames/gnfinder)"
-			doc := gndoc.New(tikaURL, gndoc.OptUserAgent(ua))
-			txt, dur.TextExtraction, err = doc.TextFromURL(url)
+			doc, dur.TextExtraction, err = textFromURL(url, cfg)
 		} else {
-			txt, filename, dur.TextExtraction, err = textFromFile(c, tikaURL)
+			doc, filename, dur.TextExtraction, err = textFromFile(c, cfg)
 		}
 		if err != nil {
 			return err
 		}
 
-		out, dur.NameFinding = findNames(gnf, filename, txt)
+		out, dur.NameFinding = findNames(gnf, filename, doc)
 		dur.Verification = out.NameVerifSec
 		dur.Total = dur.NameFinding + dur.TextExtraction + dur.Verification
 		data := Data{
@@ -124,17 +127,19 @@ func getOpts(params api.FinderParams) []config.Option {
 		config.OptDataSources(params.Sources),
 		config.OptWithAllMatches(params.AllMatches),
 		config.OptWithBayesOddsDetails(params.OddsDetails),
+		config.OptMarkup(getMarkup(params.Markup)),
 	}
 }
 
 // findNames finds names and returns duration of name-finding.
 func findNames(
 	gnf gnfinder.GNfinder,
-	file, txt string,
+	file string,
+	doc extract.Document,
 ) (output.Output, float32) {
 	start := time.Now()
 	cfg := gnf.GetConfig()
-	res := gnf.Find(file, txt)
+	res := gnf.FindWithLayout(file, doc.Text, doc.Layout)
 	dur := float32(time.Since(start)) / float32(time.Second)
 	if cfg.WithVerification {
 		sources := cfg.DataSources
@@ -144,36 +149,86 @@ func findNames(
 		res.MergeVerification(verifiedNames, stats, durVerif)
 	}
 	if cfg.IncludeInputText {
-		res.InputText = txt
+		res.InputText = doc.Text
 	}
 	return res, float32(dur)
 }
 
-// textFromFile converts uploaded file into text, returns UTF-8 encoded
-// text of the file content, duration of conversion, and a potential error.
+// textFromFile converts uploaded file into text, returns the document
+// with UTF-8 encoded text of the file content, duration of conversion,
+// and a potential error.
 func textFromFile(
 	c echo.Context,
-	tikaURL string,
-) (string, string, float32, error) {
+	cfg config.Config,
+) (extract.Document, string, float32, error) {
+	var doc extract.Document
 	start := time.Now()
-	doc := gndoc.New(tikaURL)
 	file, err := c.FormFile("file")
 	if err != nil {
-		return "", "", 0, err
+		return doc, "", 0, err
 	}
 
 	filename := file.Filename
 
 	f, err := file.Open()
 	if err != nil {
-		return "", filename, 0, err
+		return doc, filename, 0, err
+	}
+	defer f.Close()
+
+	raw, err := extract.ReadAll(f, cfg.MaxFileSize)
+	if err != nil {
+		return doc, filename, 0, err
+	}
+
+	doc, err = rawToDoc(raw, cfg, gndoc.New(cfg.TikaURL).GetText)
+	if err != nil {
+		return doc, filename, 0, err
+	}
+
+	dur := float32(time.Since(start)) / float32(time.Second)
+	return doc, filename, dur, nil
+}
+
+// textFromURL downloads content of a URL and converts it into text,
+// returns the document with UTF-8 encoded text, duration of conversion
+// and a potential error.
+func textFromURL(
+	url string,
+	cfg config.Config,
+) (extract.Document, float32, error) {
+	start := time.Now()
+	ua := "gnfinder/" + gnfinder.Version + " (https://github.com/gnames/gnfinder)"
+	raw, err := extract.Download(url, ua, cfg.MaxFileSize)
+	if err != nil {
+		return extract.Document{}, 0, err
 	}
 
-	txt, err := doc.GetText(f)
+	gnd := gndoc.New(cfg.TikaURL, gndoc.OptUserAgent(ua))
+	doc, err := rawToDoc(raw, cfg, gnd.GetText)
 	if err != nil {
-		return "", filename, 0, err
+		return doc, 0, err
 	}
 
 	dur := float32(time.Since(start)) / float32(time.Second)
-	return txt, filename, dur, nil
+	return doc, dur, nil
+}
+
+// rawToDoc converts plain texts, HTML, DOCX, ODT, EPUB and PDF locally,
+// and other formats using Apache Tika service. Marked-up texts are
+// returned as is, so the finder can use their italics, and offsets of
+// names refer to the original markup.
+func rawToDoc(
+	raw []byte,
+	cfg config.Config,
+	tikaText func(io.Reader) (string, error),
+) (extract.Document, error) {
+	if cfg.Markup != markup.NoMarkup {
+		return extract.Document{Text: string(raw)}, nil
+	}
+	doc, err := extract.Extract(raw, cfg.MaxFileSize)
+	if errors.Is(err, extract.ErrUnsupported) {
+		doc.Text, err = tikaText(bytes.NewReader(raw))
+	}
+	return doc, err
 }